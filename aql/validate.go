@@ -0,0 +1,86 @@
+package aql
+
+import (
+	"fmt"
+	structpb "github.com/golang/protobuf/ptypes/struct"
+	"regexp"
+	"strings"
+)
+
+var graphNameRe = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// ValidateGraphName returns an error if the graph name is empty or contains
+// characters other than letters, numbers, '_' and '-'
+func ValidateGraphName(graph string) error {
+	if graph == "" {
+		return fmt.Errorf("invalid graph name: graph name cannot be empty")
+	}
+	if !graphNameRe.MatchString(graph) {
+		return fmt.Errorf("invalid graph name %s: only letters, numbers, '_' and '-' are allowed", graph)
+	}
+	return nil
+}
+
+// ValidateLabel returns an error if a vertex or edge label starts with '$' or
+// contains a null character. Labels are stored as values, so '.' is allowed,
+// but a string starting with '$' is read as a field path by mongo aggregation
+// stages
+func ValidateLabel(label string) error {
+	if strings.HasPrefix(label, "$") {
+		return fmt.Errorf("invalid label %s: labels cannot start with '$'", label)
+	}
+	if strings.Contains(label, "\x00") {
+		return fmt.Errorf("invalid label %q: labels cannot contain null characters", label)
+	}
+	return nil
+}
+
+// ValidateFieldName returns an error if a data field name is empty, starts
+// with '$' or contains '.' or a null character. '.' separates the elements
+// of a field path, so a field containing it could never be queried
+func ValidateFieldName(field string) error {
+	if field == "" {
+		return fmt.Errorf("invalid field name: field name cannot be empty")
+	}
+	if strings.HasPrefix(field, "$") {
+		return fmt.Errorf("invalid field name %s: field names cannot start with '$'", field)
+	}
+	if strings.Contains(field, ".") {
+		return fmt.Errorf("invalid field name %s: field names cannot contain '.'", field)
+	}
+	if strings.Contains(field, "\x00") {
+		return fmt.Errorf("invalid field name %q: field names cannot contain null characters", field)
+	}
+	return nil
+}
+
+// ValidateData checks every field name in `data`, including the fields of
+// nested structs and structs inside lists
+func ValidateData(data *structpb.Struct) error {
+	if data == nil {
+		return nil
+	}
+	for k, v := range data.Fields {
+		if err := ValidateFieldName(k); err != nil {
+			return err
+		}
+		if err := validateValue(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateValue(v *structpb.Value) error {
+	switch x := v.GetKind().(type) {
+	case *structpb.Value_StructValue:
+		return ValidateData(x.StructValue)
+	case *structpb.Value_ListValue:
+		for _, i := range x.ListValue.GetValues() {
+			if err := validateValue(i); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package aql
+
+import (
+	"github.com/bmeg/arachne/protoutil"
+	"strings"
+	"testing"
+)
+
+func TestValidateGraphName(t *testing.T) {
+	for _, g := range []string{"test", "test_graph-1"} {
+		if err := ValidateGraphName(g); err != nil {
+			t.Errorf("unexpected error for %s: %s", g, err)
+		}
+	}
+	tests := map[string]string{
+		"":          "graph name cannot be empty",
+		"bad.graph": "only letters, numbers, '_' and '-' are allowed",
+		"bad graph": "only letters, numbers, '_' and '-' are allowed",
+	}
+	for g, msg := range tests {
+		err := ValidateGraphName(g)
+		if err == nil || !strings.Contains(err.Error(), msg) {
+			t.Errorf("expected error containing %q for %q, got %v", msg, g, err)
+		}
+	}
+}
+
+func TestValidateLabel(t *testing.T) {
+	for _, l := range []string{"Person", "http://www.w3.org/2000/01/rdf-schema#label"} {
+		if err := ValidateLabel(l); err != nil {
+			t.Errorf("unexpected error for %s: %s", l, err)
+		}
+	}
+	tests := map[string]string{
+		"$label":   "labels cannot start with '$'",
+		"bad\x00l": "labels cannot contain null characters",
+	}
+	for l, msg := range tests {
+		err := ValidateLabel(l)
+		if err == nil || !strings.Contains(err.Error(), msg) {
+			t.Errorf("expected error containing %q for %q, got %v", msg, l, err)
+		}
+	}
+}
+
+func TestValidateFieldName(t *testing.T) {
+	tests := map[string]string{
+		"":         "field name cannot be empty",
+		"$field":   "field names cannot start with '$'",
+		"a.b":      "field names cannot contain '.'",
+		"bad\x00f": "field names cannot contain null characters",
+	}
+	for f, msg := range tests {
+		err := ValidateFieldName(f)
+		if err == nil || !strings.Contains(err.Error(), msg) {
+			t.Errorf("expected error containing %q for %q, got %v", msg, f, err)
+		}
+	}
+}
+
+func TestValidateNestedData(t *testing.T) {
+	data := protoutil.AsStruct(map[string]interface{}{
+		"ok": map[string]interface{}{
+			"list": []interface{}{map[string]interface{}{"a.b": 1}},
+		},
+	})
+	err := ValidateData(data)
+	if err == nil || !strings.Contains(err.Error(), "field names cannot contain '.'") {
+		t.Errorf("expected nested field error, got %v", err)
+	}
+	if err := ValidateData(protoutil.AsStruct(map[string]interface{}{"a": 1})); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
@@ -6,13 +6,42 @@ import (
 	"github.com/bmeg/arachne/kvgraph"
 	"github.com/dgraph-io/badger"
 	"github.com/dgraph-io/badger/options"
+	"io"
 	"log"
 	"os"
+	"time"
 )
 
+// Options configures the badger database opened by BadgerBuilderWithOptions.
+// Zero values keep the badger defaults
+type Options struct {
+	// ValueLogFileSize is the maximum size in bytes of a single value log file
+	ValueLogFileSize int
+	// MaxTableSize is the size in bytes of each LSM table
+	MaxTableSize int64
+	// SyncWrites makes every write wait for the value log to be synced to disk
+	SyncWrites bool
+	// GCInterval, when set, starts a background goroutine that runs value
+	// log garbage collection at this interval until the store is closed
+	GCInterval time.Duration
+	// GCDiscardRatio is the fraction of a value log file that must be stale
+	// before it is rewritten. Defaults to DefaultGCDiscardRatio
+	GCDiscardRatio float64
+}
+
+// DefaultGCDiscardRatio is the discard ratio used when Options.GCDiscardRatio
+// is not set
+var DefaultGCDiscardRatio = 0.5
+
 // BadgerBuilder creates new badger interface at `path`
 // driver at `path`
 func BadgerBuilder(path string) (kvgraph.KVInterface, error) {
+	return BadgerBuilderWithOptions(path, Options{})
+}
+
+// BadgerBuilderWithOptions creates new badger interface at `path`, tuned
+// using `conf`
+func BadgerBuilderWithOptions(path string, conf Options) (kvgraph.KVInterface, error) {
 	log.Printf("Starting BadgerDB")
 	_, err := os.Stat(path)
 	if os.IsNotExist(err) {
@@ -24,11 +53,28 @@ func BadgerBuilder(path string) (kvgraph.KVInterface, error) {
 	opts.TableLoadingMode = options.MemoryMap
 	opts.Dir = path
 	opts.ValueDir = path
+	if conf.ValueLogFileSize > 0 {
+		opts.ValueLogFileSize = conf.ValueLogFileSize
+	}
+	if conf.MaxTableSize > 0 {
+		opts.MaxTableSize = conf.MaxTableSize
+	}
+	if conf.SyncWrites {
+		opts.SyncWrites = true
+	}
 	db, err := badger.Open(opts)
 	if err != nil {
 		log.Printf("Error: %s", err)
 	}
-	o := &BadgerKV{db: db}
+	o := &BadgerKV{db: db, discardRatio: conf.GCDiscardRatio}
+	if o.discardRatio <= 0 {
+		o.discardRatio = DefaultGCDiscardRatio
+	}
+	if conf.GCInterval > 0 && db != nil {
+		o.gcStop = make(chan bool)
+		o.gcDone = make(chan bool)
+		go o.runGC(conf.GCInterval)
+	}
 	return o, nil
 }
 
@@ -42,14 +88,65 @@ func bytesCopy(in []byte) []byte {
 
 // BadgerKV is an implementation of the KVStore for badger
 type BadgerKV struct {
-	db *badger.DB
+	db           *badger.DB
+	discardRatio float64
+	gcStop       chan bool
+	gcDone       chan bool
+}
+
+// GC runs value log garbage collection, rewriting value log files until
+// none are left with enough stale data to be worth reclaiming
+func (badgerkv *BadgerKV) GC() error {
+	for {
+		err := badgerkv.db.RunValueLogGC(badgerkv.discardRatio)
+		if err == badger.ErrNoRewrite {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// runGC periodically calls GC until gcStop is closed
+func (badgerkv *BadgerKV) runGC(interval time.Duration) {
+	defer close(badgerkv.gcDone)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-badgerkv.gcStop:
+			return
+		case <-ticker.C:
+			if err := badgerkv.GC(); err != nil {
+				log.Printf("Badger GC Error: %s", err)
+			}
+		}
+	}
 }
 
 // Close closes the boltdb
 func (badgerkv *BadgerKV) Close() error {
+	if badgerkv.gcStop != nil {
+		close(badgerkv.gcStop)
+		<-badgerkv.gcDone
+		badgerkv.gcStop = nil
+	}
 	return badgerkv.db.Close()
 }
 
+// Backup writes a full snapshot of the store to `w` in badger's native
+// backup format
+func (badgerkv *BadgerKV) Backup(w io.Writer) error {
+	_, err := badgerkv.db.Backup(w, 0)
+	return err
+}
+
+// Restore loads a snapshot written by Backup into the store
+func (badgerkv *BadgerKV) Restore(r io.Reader) error {
+	return badgerkv.db.Load(r)
+}
+
 // Delete removes a key/value from a kvstore
 func (badgerkv *BadgerKV) Delete(id []byte) error {
 	err := badgerkv.db.Update(func(tx *badger.Txn) error {
@@ -0,0 +1,90 @@
+package badgerdb
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/bmeg/arachne/kvgraph"
+	"os"
+	"testing"
+	"time"
+)
+
+func dumpKV(kv kvgraph.KVInterface) map[string]string {
+	out := map[string]string{}
+	kv.View(func(it kvgraph.KVIterator) error {
+		for it.Seek([]byte{}); it.Valid(); it.Next() {
+			v, _ := it.Value()
+			out[string(it.Key())] = string(v)
+		}
+		return nil
+	})
+	return out
+}
+
+func TestBackupRestore(t *testing.T) {
+	defer os.RemoveAll("backup_test.db")
+	defer os.RemoveAll("restore_test.db")
+
+	src, err := BadgerBuilder("backup_test.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100; i++ {
+		src.Set([]byte(fmt.Sprintf("key%d", i)), []byte(fmt.Sprintf("value%d", i)))
+	}
+	src.Delete([]byte("key0"))
+	expected := dumpKV(src)
+
+	buf := &bytes.Buffer{}
+	if err := src.(*BadgerKV).Backup(buf); err != nil {
+		t.Fatal(err)
+	}
+	src.Close()
+
+	dst, err := BadgerBuilder("restore_test.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dst.Close()
+	if err := dst.(*BadgerKV).Restore(buf); err != nil {
+		t.Fatal(err)
+	}
+	restored := dumpKV(dst)
+
+	if len(restored) != len(expected) || len(expected) != 99 {
+		t.Errorf("restored %d keys, expected %d", len(restored), len(expected))
+	}
+	for k, v := range expected {
+		if restored[k] != v {
+			t.Errorf("wrong value for %s: %s != %s", k, restored[k], v)
+		}
+	}
+}
+
+func TestGCStopsOnClose(t *testing.T) {
+	defer os.RemoveAll("gc_test.db")
+
+	kv, err := BadgerBuilderWithOptions("gc_test.db", Options{GCInterval: time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bkv := kv.(*BadgerKV)
+	for i := 0; i < 100; i++ {
+		kv.Set([]byte(fmt.Sprintf("key%d", i)), []byte(fmt.Sprintf("value%d", i)))
+		kv.Delete([]byte(fmt.Sprintf("key%d", i)))
+	}
+	// give the GC goroutine time to run while the store is in use
+	time.Sleep(20 * time.Millisecond)
+	gcDone := bkv.gcDone
+	if err := kv.Close(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-gcDone:
+	default:
+		t.Error("GC goroutine still running after Close")
+	}
+	if bkv.gcStop != nil {
+		t.Error("GC stop channel not cleared by Close")
+	}
+}
@@ -2,6 +2,7 @@ package server
 
 import (
 	"github.com/bmeg/arachne/graphserver"
+	"github.com/bmeg/arachne/mongo"
 	"github.com/spf13/cobra"
 	"log"
 	"os"
@@ -12,8 +13,11 @@ import (
 var httpPort = "8201"
 var rpcPort = "8202"
 var dbPath = "graph.db"
-var dbName = "arachne"
+var dbName string
 var mongoURL string
+var mongoReplicaSet string
+var mongoTLS bool
+var mongoTLSCAFile string
 var boltPath string
 var rocksPath string
 
@@ -30,7 +34,13 @@ var Cmd = &cobra.Command{
 
 		var server *graphserver.ArachneServer = nil
 		if mongoURL != "" {
-			server = graphserver.NewArachneMongoServer(mongoURL, dbName)
+			server = graphserver.NewArachneMongoServer(mongo.Config{
+				URL:            mongoURL,
+				DBName:         dbName,
+				ReplicaSetName: mongoReplicaSet,
+				TLS:            mongoTLS,
+				TLSCAFile:      mongoTLSCAFile,
+			})
 		} else if boltPath != "" {
 			server = graphserver.NewArachneBoltServer(boltPath)
 		} else if rocksPath != "" {
@@ -59,8 +69,11 @@ func init() {
 	flags.StringVar(&httpPort, "port", httpPort, "HTTP Port")
 	flags.StringVar(&rpcPort, "rpc", rpcPort, "TCP+RPC Port")
 	flags.StringVar(&dbPath, "db", "arachne.db", "DB Path")
-	flags.StringVar(&mongoURL, "mongo", "", "Mongo URL")
-	flags.StringVar(&dbName, "name", "arachne", "DB Name")
+	flags.StringVar(&mongoURL, "mongo", "", "Mongo URL (comma separated hosts for a replica set)")
+	flags.StringVar(&mongoReplicaSet, "mongo-replica-set", "", "Mongo Replica Set Name")
+	flags.BoolVar(&mongoTLS, "mongo-tls", false, "Use TLS to connect to Mongo")
+	flags.StringVar(&mongoTLSCAFile, "mongo-tls-ca", "", "Mongo TLS CA File")
+	flags.StringVar(&dbName, "name", "", "DB Name (defaults to the database in the Mongo URL, or arachne)")
 	flags.StringVar(&boltPath, "bolt", "", "Bolt DB Path")
 	flags.StringVar(&rocksPath, "rocks", "", "RocksDB Path")
 }
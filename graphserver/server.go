@@ -6,6 +6,7 @@ import (
 	_ "github.com/bmeg/arachne/badgerdb" // import so badger will register itself
 	_ "github.com/bmeg/arachne/boltdb"   // import so bolt will register itself
 	"github.com/bmeg/arachne/kvgraph"
+	_ "github.com/bmeg/arachne/memkv" // import so the in-memory store will register itself
 	"github.com/bmeg/arachne/mongo"
 	_ "github.com/bmeg/arachne/rocksdb" // import so rocks will register itself
 	"golang.org/x/net/context"
@@ -22,9 +23,14 @@ type ArachneServer struct {
 
 // NewArachneMongoServer initializes a GRPC server that uses the mongo driver
 // to connect to the graph store
-func NewArachneMongoServer(url string, database string) *ArachneServer {
+func NewArachneMongoServer(conf mongo.Config) *ArachneServer {
+	a, err := mongo.NewArachne(conf)
+	if err != nil {
+		log.Printf("Error Starting Mongo: %s", err)
+		return nil
+	}
 	return &ArachneServer{
-		engine: NewGraphEngine(mongo.NewArachne(url, database)),
+		engine: NewGraphEngine(a),
 	}
 }
 
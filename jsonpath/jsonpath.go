@@ -5,6 +5,8 @@ import (
 	structpb "github.com/golang/protobuf/ptypes/struct"
 	"github.com/oliveagle/jsonpath"
 	"reflect"
+	"strconv"
+	"strings"
 )
 
 // Operator the type of comparison operation to run
@@ -42,3 +44,34 @@ func CompareFields(a, b *structpb.Struct, aPath, bPath string, op Operator) (boo
 
 	return false, nil
 }
+
+//GetFieldValue returns the value found in `doc` at the dotted `path`, for
+//example "data.name.first". A leading "$." is ignored and numeric path
+//elements index into lists. The second result is false if any element of the
+//path is missing
+func GetFieldValue(doc map[string]interface{}, path string) (interface{}, bool) {
+	path = strings.TrimPrefix(path, "$.")
+	if path == "" {
+		return nil, false
+	}
+	var cur interface{} = doc
+	for _, key := range strings.Split(path, ".") {
+		switch v := cur.(type) {
+		case map[string]interface{}:
+			next, ok := v[key]
+			if !ok {
+				return nil, false
+			}
+			cur = next
+		case []interface{}:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil, false
+			}
+			cur = v[i]
+		default:
+			return nil, false
+		}
+	}
+	return cur, true
+}
@@ -62,3 +62,26 @@ func TestCompare(t *testing.T) {
 	}
 
 }
+
+func TestGetFieldValue(t *testing.T) {
+	doc := map[string]interface{}{
+		"data": map[string]interface{}{
+			"name": map[string]interface{}{"first": "Alice"},
+			"tags": []interface{}{"a", map[string]interface{}{"b": 2}},
+		},
+	}
+	if v, ok := GetFieldValue(doc, "data.name.first"); !ok || v != "Alice" {
+		t.Errorf("wrong value for data.name.first: %v", v)
+	}
+	if v, ok := GetFieldValue(doc, "$.data.name.first"); !ok || v != "Alice" {
+		t.Errorf("wrong value for $.data.name.first: %v", v)
+	}
+	if v, ok := GetFieldValue(doc, "data.tags.1.b"); !ok || v != 2 {
+		t.Errorf("wrong value for data.tags.1.b: %v", v)
+	}
+	for _, p := range []string{"data.missing.first", "data.name.first.x", "data.tags.5", "data.tags.x", "", "data..name"} {
+		if _, ok := GetFieldValue(doc, p); ok {
+			t.Errorf("expected %q to be missing", p)
+		}
+	}
+}
@@ -1,9 +1,11 @@
 package kvgraph
 
 import (
+	"bytes"
 	"fmt"
 	"github.com/bmeg/arachne/gdbi"
 	"github.com/bmeg/arachne/timestamp"
+	"log"
 )
 
 // KVBuilder is function implemented by the various key/value storage drivers
@@ -79,8 +81,29 @@ func NewKVArachne(name string, path string) (gdbi.ArachneInterface, error) {
 func NewKVGraph(kv KVInterface) gdbi.ArachneInterface {
 	ts := timestamp.NewTimestamp()
 	o := &KVGraph{kv: kv, ts: &ts}
+	o.loadTimestamps()
 	for _, i := range o.GetGraphs() {
-		o.ts.Touch(i)
+		if o.ts.Get(i) == "" {
+			o.ts.Touch(i)
+		}
 	}
 	return o
 }
+
+// loadTimestamps restores the timestamps saved by the last Close. The saved
+// copy is removed once loaded so that, if the process exits without closing
+// cleanly, the graphs are given fresh timestamps on the next start
+func (kgraph *KVGraph) loadTimestamps() {
+	var data []byte
+	kgraph.kv.View(func(it KVIterator) error {
+		data, _ = it.Get(TimestampKey())
+		return nil
+	})
+	if data == nil {
+		return
+	}
+	if err := kgraph.ts.Load(bytes.NewReader(data)); err != nil {
+		log.Printf("Unable to load saved timestamps: %s", err)
+	}
+	kgraph.kv.Delete(TimestampKey())
+}
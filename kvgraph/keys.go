@@ -9,6 +9,7 @@ var vertexPrefix = []byte("v")
 var edgePrefix = []byte("e")
 var srcEdgePrefix = []byte("s")
 var dstEdgePrefix = []byte("d")
+var timestampKey = []byte("t")
 
 var edgeSingle byte = 0x01
 var edgeBundle byte = 0x02
@@ -18,6 +19,12 @@ func GraphPrefix() []byte {
 	return graphPrefix
 }
 
+// TimestampKey returns the key the graph timestamps are saved under when the
+// driver is closed
+func TimestampKey() []byte {
+	return timestampKey
+}
+
 // GraphKey produces the byte key for a particular graph
 func GraphKey(graph string) []byte {
 	return bytes.Join([][]byte{graphPrefix, []byte(graph)}, []byte{0})
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"github.com/bmeg/arachne/aql"
 	"github.com/bmeg/arachne/gdbi"
+	"github.com/bmeg/arachne/timestamp"
 	proto "github.com/golang/protobuf/proto"
 	"math/rand"
 )
@@ -54,8 +55,33 @@ func (kgraph *KVGraph) Query(graph string) gdbi.QueryInterface {
 	return kgraph.Graph(graph).Query()
 }
 
-// Close the connection
+// TimestampVertices returns the time the vertices of `graph` were last modified
+func (kgraph *KVGraph) TimestampVertices(graph string) string {
+	return kgraph.ts.GetElement(graph, timestamp.ElementVertex)
+}
+
+// TimestampEdges returns the time the edges of `graph` were last modified
+func (kgraph *KVGraph) TimestampEdges(graph string) string {
+	return kgraph.ts.GetElement(graph, timestamp.ElementEdge)
+}
+
+// Watch returns a channel that receives an event each time `graph` is
+// modified. Pass the channel to Unwatch to stop receiving events
+func (kgraph *KVGraph) Watch(graph string) <-chan struct{} {
+	return kgraph.ts.Watch(graph)
+}
+
+// Unwatch stops the events sent to a channel returned by Watch
+func (kgraph *KVGraph) Unwatch(graph string, c <-chan struct{}) {
+	kgraph.ts.Unwatch(graph, c)
+}
+
+// Close the connection, saving the graph timestamps so they survive a restart
 func (kgraph *KVGraph) Close() {
+	buf := &bytes.Buffer{}
+	if err := kgraph.ts.Save(buf); err == nil {
+		kgraph.kv.Set(TimestampKey(), buf.Bytes())
+	}
 	kgraph.kv.Close()
 }
 
@@ -94,7 +120,7 @@ func (kgdb *KVInterfaceGDB) SetVertex(vertexArray []*aql.Vertex) error {
 				return err
 			}
 		}
-		kgdb.ts.Touch(kgdb.graph)
+		kgdb.ts.Touch(kgdb.graph, timestamp.ElementVertex)
 		return nil
 	})
 	return nil
@@ -142,7 +168,7 @@ func (kgdb *KVInterfaceGDB) SetEdge(edgeArray []*aql.Edge) error {
 			if err != nil {
 				return err
 			}
-			kgdb.ts.Touch(kgdb.graph)
+			kgdb.ts.Touch(kgdb.graph, timestamp.ElementEdge)
 		}
 		return nil
 	})
@@ -171,7 +197,7 @@ func (kgdb *KVInterfaceGDB) SetBundle(bundle aql.Bundle) error {
 	if err := kgdb.kv.Set(skey, []byte{}); err != nil {
 		return err
 	}
-	kgdb.ts.Touch(kgdb.graph)
+	kgdb.ts.Touch(kgdb.graph, timestamp.ElementEdge)
 	return nil
 }
 
@@ -204,7 +230,7 @@ func (kgdb *KVInterfaceGDB) DelEdge(eid string) error {
 	if err := kgdb.kv.Delete(dkey); err != nil {
 		return err
 	}
-	kgdb.ts.Touch(kgdb.graph)
+	kgdb.ts.Touch(kgdb.graph, timestamp.ElementEdge)
 	return nil
 }
 
@@ -230,7 +256,7 @@ func (kgdb *KVInterfaceGDB) DelBundle(eid string) error {
 	if err := kgdb.kv.Delete(skey); err != nil {
 		return err
 	}
-	kgdb.ts.Touch(kgdb.graph)
+	kgdb.ts.Touch(kgdb.graph, timestamp.ElementEdge)
 	return nil
 }
 
@@ -0,0 +1,243 @@
+package memkv
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/bmeg/arachne/kvgraph"
+	"log"
+	"sort"
+	"sync"
+)
+
+// MemBuilder creates a new in-memory kv interface. The `path` argument is
+// ignored, nothing is written to disk
+func MemBuilder(path string) (kvgraph.KVInterface, error) {
+	log.Printf("Starting in-memory KV")
+	return NewMemKV(), nil
+}
+
+var loaded = kvgraph.AddKVDriver("memory", MemBuilder)
+
+// MemKV is an implementation of the KVStore that keeps everything in a sorted
+// in-memory map
+type MemKV struct {
+	lock   sync.RWMutex
+	keys   []string
+	values map[string][]byte
+}
+
+// NewMemKV creates an empty in-memory kv store
+func NewMemKV() *MemKV {
+	return &MemKV{keys: []string{}, values: map[string][]byte{}}
+}
+
+func copyBytes(in []byte) []byte {
+	out := make([]byte, len(in))
+	copy(out, in)
+	return out
+}
+
+// set must be called with the write lock held
+func (memkv *MemKV) set(key string, val []byte) {
+	if _, ok := memkv.values[key]; !ok {
+		i := sort.SearchStrings(memkv.keys, key)
+		memkv.keys = append(memkv.keys, "")
+		copy(memkv.keys[i+1:], memkv.keys[i:])
+		memkv.keys[i] = key
+	}
+	memkv.values[key] = copyBytes(val)
+}
+
+// delete must be called with the write lock held
+func (memkv *MemKV) delete(key string) {
+	if _, ok := memkv.values[key]; !ok {
+		return
+	}
+	delete(memkv.values, key)
+	i := sort.SearchStrings(memkv.keys, key)
+	memkv.keys = append(memkv.keys[:i], memkv.keys[i+1:]...)
+}
+
+// get returns a copy of the value stored at `key`
+func (memkv *MemKV) get(key []byte) ([]byte, bool) {
+	memkv.lock.RLock()
+	defer memkv.lock.RUnlock()
+	v, ok := memkv.values[string(key)]
+	if !ok {
+		return nil, false
+	}
+	return copyBytes(v), true
+}
+
+// Close drops all stored values
+func (memkv *MemKV) Close() error {
+	memkv.lock.Lock()
+	defer memkv.lock.Unlock()
+	memkv.keys = []string{}
+	memkv.values = map[string][]byte{}
+	return nil
+}
+
+// Delete removes a key/value from a kvstore
+func (memkv *MemKV) Delete(id []byte) error {
+	memkv.lock.Lock()
+	defer memkv.lock.Unlock()
+	memkv.delete(string(id))
+	return nil
+}
+
+// DeletePrefix deletes all elements in kvstore that begin with prefix `id`
+func (memkv *MemKV) DeletePrefix(id []byte) error {
+	memkv.lock.Lock()
+	defer memkv.lock.Unlock()
+	prefix := string(id)
+	i := sort.SearchStrings(memkv.keys, prefix)
+	j := i
+	for j < len(memkv.keys) && bytes.HasPrefix([]byte(memkv.keys[j]), id) {
+		delete(memkv.values, memkv.keys[j])
+		j++
+	}
+	memkv.keys = append(memkv.keys[:i], memkv.keys[j:]...)
+	return nil
+}
+
+// HasKey returns true if the key exists in the kv store
+func (memkv *MemKV) HasKey(id []byte) bool {
+	_, ok := memkv.get(id)
+	return ok
+}
+
+// Set value in kv store
+func (memkv *MemKV) Set(id []byte, val []byte) error {
+	memkv.lock.Lock()
+	defer memkv.lock.Unlock()
+	memkv.set(string(id), val)
+	return nil
+}
+
+type memTransaction struct {
+	kv      *MemKV
+	set     map[string][]byte
+	deleted map[string]bool
+	order   []string
+}
+
+// Delete removes key `id` from the kv store
+func (memTrans *memTransaction) Delete(id []byte) error {
+	k := string(id)
+	delete(memTrans.set, k)
+	memTrans.deleted[k] = true
+	memTrans.order = append(memTrans.order, k)
+	return nil
+}
+
+func (memTrans *memTransaction) Set(key, val []byte) error {
+	k := string(key)
+	delete(memTrans.deleted, k)
+	memTrans.set[k] = copyBytes(val)
+	memTrans.order = append(memTrans.order, k)
+	return nil
+}
+
+func (memTrans *memTransaction) HasKey(id []byte) bool {
+	k := string(id)
+	if _, ok := memTrans.set[k]; ok {
+		return true
+	}
+	if memTrans.deleted[k] {
+		return false
+	}
+	return memTrans.kv.HasKey(id)
+}
+
+// Update runs an alteration transaction on the in-memory kv store. Changes are
+// only applied if `u` returns without error
+func (memkv *MemKV) Update(u func(tx kvgraph.KVTransaction) error) error {
+	ktx := &memTransaction{kv: memkv, set: map[string][]byte{}, deleted: map[string]bool{}}
+	if err := u(ktx); err != nil {
+		return err
+	}
+	memkv.lock.Lock()
+	defer memkv.lock.Unlock()
+	for _, k := range ktx.order {
+		if v, ok := ktx.set[k]; ok {
+			memkv.set(k, v)
+		} else if ktx.deleted[k] {
+			memkv.delete(k)
+		}
+	}
+	return nil
+}
+
+// memIterator walks a snapshot of the keys taken when View was called, values
+// are read from the live store
+type memIterator struct {
+	kv    *MemKV
+	keys  []string
+	pos   int
+	key   []byte
+	value []byte
+}
+
+// Get retrieves the value of key `id`
+func (memIt *memIterator) Get(id []byte) ([]byte, error) {
+	v, ok := memIt.kv.get(id)
+	if !ok {
+		return nil, fmt.Errorf("Not Found")
+	}
+	return v, nil
+}
+
+// Key returns the key the iterator is currently pointed at
+func (memIt *memIterator) Key() []byte {
+	return memIt.key
+}
+
+// Value returns the value the iterator is currently pointed at
+func (memIt *memIterator) Value() ([]byte, error) {
+	return memIt.value, nil
+}
+
+// load points the iterator at the first key at or after position `pos` that
+// is still present in the store
+func (memIt *memIterator) load(pos int) {
+	for memIt.pos = pos; memIt.pos < len(memIt.keys); memIt.pos++ {
+		k := memIt.keys[memIt.pos]
+		if v, ok := memIt.kv.get([]byte(k)); ok {
+			memIt.key = []byte(k)
+			memIt.value = v
+			return
+		}
+	}
+	memIt.key = nil
+	memIt.value = nil
+}
+
+// Next moves the iterator to the next key
+func (memIt *memIterator) Next() error {
+	memIt.load(memIt.pos + 1)
+	return nil
+}
+
+// Seek moves the iterator to a new location
+func (memIt *memIterator) Seek(id []byte) error {
+	memIt.load(sort.SearchStrings(memIt.keys, string(id)))
+	if memIt.key == nil {
+		return fmt.Errorf("Seek error")
+	}
+	return nil
+}
+
+// Valid returns true if the iterator is still at a valid location
+func (memIt *memIterator) Valid() bool {
+	return memIt.key != nil
+}
+
+// View runs an iterator on the in-memory kv store
+func (memkv *MemKV) View(u func(it kvgraph.KVIterator) error) error {
+	memkv.lock.RLock()
+	keys := make([]string, len(memkv.keys))
+	copy(keys, memkv.keys)
+	memkv.lock.RUnlock()
+	return u(&memIterator{kv: memkv, keys: keys})
+}
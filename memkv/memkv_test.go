@@ -0,0 +1,86 @@
+package memkv
+
+import (
+	"context"
+	"fmt"
+	"github.com/bmeg/arachne/aql"
+	"github.com/bmeg/arachne/kvgraph"
+	"testing"
+)
+
+func TestSortedView(t *testing.T) {
+	kv := NewMemKV()
+	for _, k := range []string{"b2", "a1", "b1", "c1"} {
+		kv.Set([]byte(k), []byte(k))
+	}
+	out := []string{}
+	kv.View(func(it kvgraph.KVIterator) error {
+		for it.Seek([]byte("b")); it.Valid(); it.Next() {
+			out = append(out, string(it.Key()))
+		}
+		return nil
+	})
+	if fmt.Sprint(out) != "[b1 b2 c1]" {
+		t.Errorf("unexpected keys: %v", out)
+	}
+}
+
+func TestDeletePrefix(t *testing.T) {
+	kv := NewMemKV()
+	for _, k := range []string{"a1", "b1", "b2", "c1"} {
+		kv.Set([]byte(k), []byte{})
+	}
+	kv.DeletePrefix([]byte("b"))
+	if kv.HasKey([]byte("b1")) || kv.HasKey([]byte("b2")) {
+		t.Error("prefix not deleted")
+	}
+	if !kv.HasKey([]byte("a1")) || !kv.HasKey([]byte("c1")) {
+		t.Error("deleted too many keys")
+	}
+}
+
+func TestUpdateRollback(t *testing.T) {
+	kv := NewMemKV()
+	kv.Update(func(tx kvgraph.KVTransaction) error {
+		tx.Set([]byte("a"), []byte("1"))
+		return fmt.Errorf("abort")
+	})
+	if kv.HasKey([]byte("a")) {
+		t.Error("failed update was applied")
+	}
+	kv.Update(func(tx kvgraph.KVTransaction) error {
+		tx.Set([]byte("a"), []byte("1"))
+		if !tx.HasKey([]byte("a")) {
+			t.Error("pending set not visible in transaction")
+		}
+		return nil
+	})
+	if !kv.HasKey([]byte("a")) {
+		t.Error("update was not applied")
+	}
+}
+
+func TestGraph(t *testing.T) {
+	db, err := kvgraph.NewKVArachne("memory", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err := db.AddGraph("test"); err != nil {
+		t.Fatal(err)
+	}
+	graph := db.Graph("test")
+	graph.SetVertex([]*aql.Vertex{{Gid: "1", Label: "Person"}, {Gid: "2", Label: "Person"}, {Gid: "3", Label: "Person"}})
+	graph.SetEdge([]*aql.Edge{{Gid: "e1", From: "1", To: "2", Label: "knows"}, {Gid: "e2", From: "2", To: "3", Label: "knows"}})
+
+	if g := db.GetGraphs(); fmt.Sprint(g) != "[test]" {
+		t.Errorf("unexpected graphs: %v", g)
+	}
+	out := []string{}
+	for row := range db.Query("test").V([]string{"1"}).Out("knows").Out("knows").Execute(context.Background()) {
+		out = append(out, row.Value.GetVertex().Gid)
+	}
+	if fmt.Sprint(out) != "[3]" {
+		t.Errorf("unexpected query result: %v", out)
+	}
+}
@@ -0,0 +1,348 @@
+package mongo
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"gopkg.in/mgo.v2"
+	"io/ioutil"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultDBName is the database used when neither Config.DBName nor the
+// connection string name one
+var DefaultDBName = "arachne"
+
+// dialTimeout matches the timeout used by mgo.Dial
+var dialTimeout = 10 * time.Second
+
+// DefaultSocketTimeout is the session socket timeout used when
+// Config.SocketTimeout is zero
+var DefaultSocketTimeout = 1 * time.Hour
+
+// DefaultSyncTimeout is the session sync timeout used when
+// Config.SyncTimeout is zero
+var DefaultSyncTimeout = 1 * time.Minute
+
+// DefaultRetryBackoff is the wait before the first reconnect attempt when
+// Config.RetryBackoff is zero
+var DefaultRetryBackoff = 100 * time.Millisecond
+
+// DefaultMaxRetryBackoff is the longest wait between reconnect attempts when
+// Config.MaxRetryBackoff is zero
+var DefaultMaxRetryBackoff = 5 * time.Second
+
+// TestedServerVersion is the oldest mongo server version the driver is tested
+// against, and the minimum required unless Config.MinServerVersion is set
+var TestedServerVersion = []int{3, 2}
+
+// Config describes how the mongo driver connects to the server
+type Config struct {
+	// URL is the address of the mongo server. A comma separated list of
+	// hosts can be given to connect to the members of a replica set. A full
+	// mongodb:// connection string is also accepted, in which case the
+	// other fields, when set, override the values found in the string
+	URL            string
+	DBName         string
+	Username       string
+	Password       string
+	ReplicaSetName string
+	// CollectionPrefix is prepended to the name of every collection the
+	// driver uses, so several sets of graphs can share one database
+	CollectionPrefix string
+	// TLS enables encrypted connections to the server. TLSCAFile is an
+	// optional PEM file of root certificates used to verify the server
+	TLS                   bool
+	TLSCAFile             string
+	TLSInsecureSkipVerify bool
+	// SocketTimeout is how long to wait on a socket read or write before
+	// treating the connection as dead. Defaults to DefaultSocketTimeout
+	SocketTimeout time.Duration
+	// SyncTimeout is how long to wait for a server to be available for an
+	// operation. Defaults to DefaultSyncTimeout
+	SyncTimeout time.Duration
+	// MaxRetries is the number of attempts made by a write that fails with a
+	// connection error. Defaults to the package level MaxRetries
+	MaxRetries int
+	// RetryBackoff is the wait before reconnecting after the first failed
+	// attempt. It doubles after each further failure, up to MaxRetryBackoff.
+	// Defaults to DefaultRetryBackoff and DefaultMaxRetryBackoff
+	RetryBackoff    time.Duration
+	MaxRetryBackoff time.Duration
+	// GraphCacheTTL, when set, caches the graph list for this long. The cache
+	// is cleared whenever this driver adds, deletes or renames a graph, but
+	// graphs changed by other clients are only seen once it expires
+	GraphCacheTTL time.Duration
+	// MinServerVersion overrides the minimum mongo server version required to
+	// connect, for example "3.0". Connecting to a server older than
+	// TestedServerVersion logs a warning
+	MinServerVersion string
+	// ReadPreference selects which replica set members serve reads. One of
+	// "primary", "primaryPreferred", "secondary", "secondaryPreferred" or
+	// "nearest". Writes always go to the primary. Defaults to "primary"
+	ReadPreference string
+	// WriteConcern sets the acknowledgement requested for vertex and edge
+	// writes. When nil the mgo default is used
+	WriteConcern *WriteConcern
+	// VertexIndexes and EdgeIndexes are created by AddGraph in addition to
+	// the default label, from and to indexes
+	VertexIndexes []Index
+	EdgeIndexes   []Index
+	// ForegroundIndexes builds the default indexes in the foreground, which
+	// is faster but blocks the collection. By default they are built in the
+	// background. Indexes in VertexIndexes and EdgeIndexes use their own
+	// Background setting
+	ForegroundIndexes bool
+	// UniqueEdges makes BuildIndexes call EnforceEdgeUniqueness, so a graph
+	// can have at most one edge of a label between two vertices
+	UniqueEdges bool
+	// DeferIndexes stops AddGraph from creating any indexes, so a bulk load
+	// can run first and call BuildIndexes afterwards
+	DeferIndexes bool
+	// Logger receives the driver's log messages. Defaults to the standard
+	// library logger
+	Logger Logger
+	// Metrics, when set, is told the duration and error of graph management
+	// calls and traversal aggregation pipelines
+	Metrics Metrics
+}
+
+// Index describes an index on a graph's vertex or edge collection. Key
+// follows the mgo.Index conventions, ie "-field" for descending order and
+// "$hashed:field" for a hashed index. Vertex and edge data fields live under
+// "data", for example "data.symbol"
+type Index struct {
+	Key        []string
+	Unique     bool
+	Sparse     bool
+	Background bool
+}
+
+func (i Index) mgoIndex() mgo.Index {
+	return mgo.Index{
+		Key:        i.Key,
+		Unique:     i.Unique,
+		Sparse:     i.Sparse,
+		Background: i.Background,
+	}
+}
+
+// WriteConcern describes the acknowledgement requested from the server for
+// writes. Writes are acknowledged by default: a W of 0 means the primary alone
+// acknowledges, as if W were 1. Set Unacknowledged to send writes without
+// waiting for the server, in which case W, J and WTimeout are ignored
+type WriteConcern struct {
+	W              int
+	J              bool
+	WTimeout       time.Duration
+	Unacknowledged bool
+}
+
+// safe converts the write concern into the mgo equivalent. A nil result
+// means writes are not acknowledged
+func (wc *WriteConcern) safe() *mgo.Safe {
+	if wc.Unacknowledged {
+		return nil
+	}
+	w := wc.W
+	if w == 0 {
+		w = 1
+	}
+	return &mgo.Safe{W: w, J: wc.J, WTimeout: int(wc.WTimeout / time.Millisecond)}
+}
+
+// addrs splits the configured URL into the list of server addresses
+func (conf Config) addrs() []string {
+	out := []string{}
+	for _, a := range strings.Split(conf.URL, ",") {
+		a = strings.TrimSpace(a)
+		if a != "" {
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
+func (conf Config) socketTimeout() time.Duration {
+	if conf.SocketTimeout == 0 {
+		return DefaultSocketTimeout
+	}
+	return conf.SocketTimeout
+}
+
+func (conf Config) syncTimeout() time.Duration {
+	if conf.SyncTimeout == 0 {
+		return DefaultSyncTimeout
+	}
+	return conf.SyncTimeout
+}
+
+func (conf Config) maxRetries() int {
+	if conf.MaxRetries <= 0 {
+		return MaxRetries
+	}
+	return conf.MaxRetries
+}
+
+// retryBackoff returns the wait after failed attempt `n`, counting from zero
+func (conf Config) retryBackoff(n int) time.Duration {
+	backoff, max := conf.RetryBackoff, conf.MaxRetryBackoff
+	if backoff == 0 {
+		backoff = DefaultRetryBackoff
+	}
+	if max == 0 {
+		max = DefaultMaxRetryBackoff
+	}
+	for i := 0; i < n && backoff < max; i++ {
+		backoff *= 2
+	}
+	if backoff > max {
+		return max
+	}
+	return backoff
+}
+
+// minServerVersion parses MinServerVersion, defaulting to TestedServerVersion
+func (conf Config) minServerVersion() ([]int, error) {
+	if conf.MinServerVersion == "" {
+		return TestedServerVersion, nil
+	}
+	out := []int{}
+	for _, p := range strings.Split(conf.MinServerVersion, ".") {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("Invalid minimum server version: %s", conf.MinServerVersion)
+		}
+		out = append(out, n)
+	}
+	return out, nil
+}
+
+func formatVersion(v []int) string {
+	s := make([]string, len(v))
+	for i := range v {
+		s[i] = strconv.Itoa(v[i])
+	}
+	return strings.Join(s, ".")
+}
+
+var readModes = map[string]mgo.Mode{
+	"":                   mgo.Primary,
+	"primary":            mgo.Primary,
+	"primaryPreferred":   mgo.PrimaryPreferred,
+	"secondary":          mgo.Secondary,
+	"secondaryPreferred": mgo.SecondaryPreferred,
+	"nearest":            mgo.Nearest,
+}
+
+func (conf Config) readMode() (mgo.Mode, error) {
+	if m, ok := readModes[conf.ReadPreference]; ok {
+		return m, nil
+	}
+	return mgo.Primary, fmt.Errorf("Unknown read preference: %s", conf.ReadPreference)
+}
+
+// dbName returns the configured database name, or DefaultDBName
+func (conf Config) dbName() string {
+	if conf.DBName != "" {
+		return conf.DBName
+	}
+	return DefaultDBName
+}
+
+// tlsConfig builds the crypto/tls configuration used when TLS is enabled
+func (conf Config) tlsConfig() (*tls.Config, error) {
+	tlsConf := &tls.Config{InsecureSkipVerify: conf.TLSInsecureSkipVerify}
+	if conf.TLSCAFile != "" {
+		pem, err := ioutil.ReadFile(conf.TLSCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to read TLS CA file %s: %s", conf.TLSCAFile, err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("No certificates found in TLS CA file %s", conf.TLSCAFile)
+		}
+		tlsConf.RootCAs = pool
+	}
+	return tlsConf, nil
+}
+
+// parseURL builds a mgo.DialInfo from a mongodb:// connection string, letting
+// the explicitly configured fields take precedence
+func (conf Config) parseURL() (*mgo.DialInfo, error) {
+	if strings.HasPrefix(conf.URL, "mongodb+srv://") {
+		return nil, fmt.Errorf("Invalid mongo URL %s: mongodb+srv:// connection strings are not supported", conf.URL)
+	}
+	info, err := mgo.ParseURL(conf.URL)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid mongo URL %s: %s", conf.URL, err)
+	}
+	if conf.DBName != "" {
+		// credentials are checked against the database named in the URL,
+		// or authSource, not the one the graphs are stored in
+		if info.Source == "" {
+			info.Source = info.Database
+			if info.Source == "" {
+				info.Source = "admin"
+			}
+		}
+		info.Database = conf.DBName
+	}
+	if info.Database == "" {
+		info.Database = DefaultDBName
+	}
+	if conf.Username != "" {
+		info.Username = conf.Username
+	}
+	if conf.Password != "" {
+		info.Password = conf.Password
+	}
+	if conf.ReplicaSetName != "" {
+		info.ReplicaSetName = conf.ReplicaSetName
+	}
+	if info.Timeout == 0 {
+		info.Timeout = dialTimeout
+	}
+	return info, nil
+}
+
+// isConnectionString returns true if the URL is a full mongo connection string
+// rather than a list of hosts
+func (conf Config) isConnectionString() bool {
+	return strings.HasPrefix(conf.URL, "mongodb://") || strings.HasPrefix(conf.URL, "mongodb+srv://")
+}
+
+// dialInfo builds the mgo.DialInfo used to open sessions
+func (conf Config) dialInfo() (*mgo.DialInfo, error) {
+	var info *mgo.DialInfo
+	if conf.isConnectionString() {
+		var err error
+		info, err = conf.parseURL()
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		info = &mgo.DialInfo{
+			Addrs:          conf.addrs(),
+			Database:       conf.dbName(),
+			Username:       conf.Username,
+			Password:       conf.Password,
+			ReplicaSetName: conf.ReplicaSetName,
+			Timeout:        dialTimeout,
+		}
+	}
+	if conf.TLS {
+		tlsConf, err := conf.tlsConfig()
+		if err != nil {
+			return nil, err
+		}
+		info.DialServer = func(addr *mgo.ServerAddr) (net.Conn, error) {
+			dialer := &net.Dialer{Timeout: dialTimeout}
+			return tls.DialWithDialer(dialer, "tcp", addr.String(), tlsConf)
+		}
+	}
+	return info, nil
+}
@@ -96,13 +96,45 @@ func PackBundle(e aql.Bundle) map[string]interface{} {
 	return o
 }
 
+// unpackData converts the data field of a mongo doc into a Struct. Decimal128
+// values are kept as decimal strings so they aren't dropped or rounded
+func unpackData(d map[string]interface{}) *structpb.Struct {
+	convertBSON(d)
+	return protoutil.AsStruct(d)
+}
+
+// convertBSON replaces the bson types in `v` that protoutil doesn't know,
+// including those in nested documents and arrays. Decimal128 values become
+// their string form and Binary values their []byte data
+func convertBSON(v interface{}) interface{} {
+	switch x := v.(type) {
+	case bson.Decimal128:
+		return x.String()
+	case bson.Binary:
+		return x.Data
+	case map[string]interface{}:
+		for k, i := range x {
+			x[k] = convertBSON(i)
+		}
+	case bson.M:
+		for k, i := range x {
+			x[k] = convertBSON(i)
+		}
+	case []interface{}:
+		for j, i := range x {
+			x[j] = convertBSON(i)
+		}
+	}
+	return v
+}
+
 // UnpackVertex takes a mongo doc and converts it into an aql.Vertex
 func UnpackVertex(i map[string]interface{}) aql.Vertex {
 	o := aql.Vertex{}
 	o.Gid = i["_id"].(string)
 	o.Label = i["label"].(string)
 	if p, ok := i["data"]; ok {
-		o.Data = protoutil.AsStruct(p.(map[string]interface{}))
+		o.Data = unpackData(p.(map[string]interface{}))
 	}
 	return o
 }
@@ -119,7 +151,7 @@ func UnpackEdge(i map[string]interface{}) aql.Edge {
 	o.Label = i["label"].(string)
 	o.From = i[fieldSrc].(string)
 	o.To = i[fieldDst].(string)
-	o.Data = protoutil.AsStruct(i["data"].(map[string]interface{}))
+	o.Data = unpackData(i["data"].(map[string]interface{}))
 	return o
 }
 
@@ -149,7 +181,7 @@ func UnpackBundle(i map[string]interface{}) aql.Bundle {
 	for i := 0; i < NWORKERS; i++ {
 		go func() {
 			for p := range p1 {
-				p2 <- pair{p.key, nil, protoutil.AsStruct(p.valueMap.(map[string]interface{}))}
+				p2 <- pair{p.key, nil, unpackData(p.valueMap.(map[string]interface{}))}
 			}
 			pclose <- true
 		}()
@@ -0,0 +1,58 @@
+package mongo
+
+import (
+	"gopkg.in/mgo.v2/bson"
+	"reflect"
+	"testing"
+)
+
+func decimal(t *testing.T, s string) bson.Decimal128 {
+	d, err := bson.ParseDecimal128(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func TestConvertBSON(t *testing.T) {
+	tests := []struct {
+		in  interface{}
+		out interface{}
+	}{
+		{decimal(t, "1234567890.123456789012345678"), "1234567890.123456789012345678"},
+		{decimal(t, "NaN"), "NaN"},
+		{decimal(t, "Inf"), "Inf"},
+		{decimal(t, "-Inf"), "-Inf"},
+		{"text", "text"},
+		{1.5, 1.5},
+		{
+			map[string]interface{}{"price": decimal(t, "0.1"), "nested": map[string]interface{}{"d": decimal(t, "2")}},
+			map[string]interface{}{"price": "0.1", "nested": map[string]interface{}{"d": "2"}},
+		},
+		{
+			[]interface{}{decimal(t, "1.5"), []interface{}{decimal(t, "-2")}, 3},
+			[]interface{}{"1.5", []interface{}{"-2"}, 3},
+		},
+		{bson.Binary{Kind: 0x80, Data: []byte{1, 2}}, []byte{1, 2}},
+	}
+	for _, test := range tests {
+		if out := convertBSON(test.in); !reflect.DeepEqual(out, test.out) {
+			t.Errorf("convertBSON(%v) = %#v, expected %#v", test.in, out, test.out)
+		}
+	}
+}
+
+func TestUnpackVertexDecimal(t *testing.T) {
+	doc := map[string]interface{}{
+		"_id":   "1",
+		"label": "Product",
+		"data":  map[string]interface{}{"price": decimal(t, "19.99"), "count": 3.0},
+	}
+	v := UnpackVertex(doc)
+	if p := v.Data.Fields["price"].GetStringValue(); p != "19.99" {
+		t.Errorf("wrong price: %v", v.Data.Fields["price"])
+	}
+	if c := v.Data.Fields["count"].GetNumberValue(); c != 3 {
+		t.Errorf("wrong count: %v", v.Data.Fields["count"])
+	}
+}
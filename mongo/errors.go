@@ -0,0 +1,76 @@
+package mongo
+
+import (
+	"errors"
+	"fmt"
+	"gopkg.in/mgo.v2"
+	"strings"
+)
+
+// ErrGraphExists is returned, unwrapped, by AddGraph, RenameGraph and
+// CopyGraph when the name of the new graph is already in use
+var ErrGraphExists = errors.New("graph already exists")
+
+// ErrDuplicateEdge is returned, wrapped in an *Error, when SetEdge would
+// create a second edge with the same from, to and label while edge
+// uniqueness is enforced
+var ErrDuplicateEdge = errors.New("duplicate edge")
+
+// ErrGraphNotFound is returned, wrapped in an *Error, when an operation
+// refers to a graph that doesn't exist
+var ErrGraphNotFound = errors.New("graph not found")
+
+// ErrInvalidName is returned, wrapped in an *Error, when a graph name fails
+// validation. The Detail of the *Error explains which rule was broken
+var ErrInvalidName = errors.New("invalid name")
+
+// Error records the driver operation and graph an error came from. Err is
+// either one of the Err values above or the error returned by mgo, and Detail
+// optionally adds a description of the cause
+type Error struct {
+	Op     string
+	Graph  string
+	Err    error
+	Detail string
+}
+
+func (e *Error) Error() string {
+	msg := e.Err.Error()
+	if e.Detail != "" {
+		msg += ": " + e.Detail
+	}
+	if e.Graph == "" {
+		return fmt.Sprintf("%s: %s", e.Op, msg)
+	}
+	return fmt.Sprintf("%s %s: %s", e.Op, e.Graph, msg)
+}
+
+// Unwrap returns the underlying error
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// wrapError wraps `err` in an *Error, returning nil if `err` is nil
+func wrapError(op, graph string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Op: op, Graph: graph, Err: err}
+}
+
+func graphNotFound(op, graph string) error {
+	return &Error{Op: op, Graph: graph, Err: ErrGraphNotFound}
+}
+
+func invalidName(op, graph string, err error) error {
+	return &Error{Op: op, Graph: graph, Err: ErrInvalidName, Detail: err.Error()}
+}
+
+// isNamespaceNotFound returns true if `err` is the server error for a
+// collection that doesn't exist
+func isNamespaceNotFound(err error) bool {
+	if q, ok := err.(*mgo.QueryError); ok && q.Code == 26 {
+		return true
+	}
+	return err != nil && strings.Contains(err.Error(), "ns not found")
+}
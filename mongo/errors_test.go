@@ -0,0 +1,23 @@
+package mongo
+
+import (
+	"errors"
+	"gopkg.in/mgo.v2"
+	"testing"
+)
+
+func TestIsNamespaceNotFound(t *testing.T) {
+	tests := map[error]bool{
+		&mgo.QueryError{Code: 26, Message: "Collection [arachne.test_vertices] not found."}: true,
+		&mgo.QueryError{Message: "ns not found"}:                                            true,
+		errors.New("ns not found"):                                                          true,
+		&mgo.QueryError{Code: 13, Message: "not authorized"}:                                false,
+		mgo.ErrNotFound: false,
+		nil:             false,
+	}
+	for err, expected := range tests {
+		if isNamespaceNotFound(err) != expected {
+			t.Errorf("isNamespaceNotFound(%v) != %v", err, expected)
+		}
+	}
+}
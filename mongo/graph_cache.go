@@ -0,0 +1,62 @@
+package mongo
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// graphCache holds the graph list for Config.GraphCacheTTL. gen is bumped by
+// each invalidate, so a list read from the server before a graph was added or
+// removed is never cached
+type graphCache struct {
+	lock    sync.Mutex
+	graphs  []string
+	expires time.Time
+	gen     uint64
+}
+
+// get returns a copy of the cached graph list, or false if it has expired
+func (c *graphCache) get() ([]string, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.graphs == nil || time.Now().After(c.expires) {
+		return nil, false
+	}
+	out := make([]string, len(c.graphs))
+	copy(out, c.graphs)
+	return out, true
+}
+
+// generation returns the value to pass to set for a read starting now
+func (c *graphCache) generation() uint64 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.gen
+}
+
+// set caches `graphs`, unless the cache was invalidated since generation
+// `gen`, when the read that produced them started
+func (c *graphCache) set(graphs []string, ttl time.Duration, gen uint64) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if gen != c.gen {
+		return
+	}
+	c.graphs = make([]string, len(graphs))
+	copy(c.graphs, graphs)
+	c.expires = time.Now().Add(ttl)
+}
+
+func (c *graphCache) invalidate() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.graphs = nil
+	c.gen++
+}
+
+// RefreshGraphs drops the cached graph list and reloads it from the server
+func (ma *Arachne) RefreshGraphs() ([]string, error) {
+	ma.graphCache.invalidate()
+	return ma.GetGraphsContext(context.Background())
+}
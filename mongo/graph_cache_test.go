@@ -0,0 +1,32 @@
+package mongo
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGraphCacheStaleSet(t *testing.T) {
+	c := graphCache{}
+	// a read starts, then a graph is added before it finishes
+	gen := c.generation()
+	c.invalidate()
+	c.set([]string{"old"}, time.Minute, gen)
+	if g, ok := c.get(); ok {
+		t.Errorf("stale graph list was cached: %v", g)
+	}
+
+	gen = c.generation()
+	c.set([]string{"new"}, time.Minute, gen)
+	if g, ok := c.get(); !ok || len(g) != 1 || g[0] != "new" {
+		t.Errorf("graph list not cached: %v", g)
+	}
+}
+
+func TestGraphCacheExpires(t *testing.T) {
+	c := graphCache{}
+	c.set([]string{"a"}, time.Millisecond, c.generation())
+	time.Sleep(5 * time.Millisecond)
+	if _, ok := c.get(); ok {
+		t.Error("expired graph list returned")
+	}
+}
@@ -0,0 +1,152 @@
+package mongo
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"github.com/bmeg/arachne/aql"
+	"github.com/golang/protobuf/jsonpb"
+	"io"
+)
+
+// readJSONL calls `fn` with each non-blank line of `r` and its line number,
+// counting from one, stopping at the first error
+func readJSONL(r io.Reader, fn func(n int, line []byte) error) error {
+	reader := bufio.NewReader(r)
+	n := 0
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			n++
+			if line = bytes.TrimSpace(line); len(line) > 0 {
+				if err := fn(n, line); err != nil {
+					return err
+				}
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("line %d: %s", n+1, err)
+		}
+	}
+}
+
+// LoadVerticesJSONL reads newline delimited JSON vertices from `r` and
+// upserts them into `graph` in batches of BatchSize. It returns the number of
+// vertices loaded. Loading stops at the first invalid record or failed batch,
+// and the error gives the line number of the record, or the line range of
+// the batch
+func (ma *Arachne) LoadVerticesJSONL(graph string, r io.Reader) (int, error) {
+	if err := ma.requireGraph("LoadVerticesJSONL", graph); err != nil {
+		return 0, err
+	}
+	g := ma.Graph(graph).(*Graph)
+	umarsh := jsonpb.Unmarshaler{AllowUnknownFields: true}
+	count := 0
+	first := 0
+	batch := make([]*aql.Vertex, 0, BatchSize)
+	flush := func(last int) error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if _, _, err := g.AddVertices(batch); err != nil {
+			return fmt.Errorf("lines %d-%d: %s", first, last, err)
+		}
+		count += len(batch)
+		batch = batch[:0]
+		return nil
+	}
+	last := 0
+	err := readJSONL(r, func(n int, line []byte) error {
+		v := &aql.Vertex{}
+		if err := umarsh.Unmarshal(bytes.NewReader(line), v); err != nil {
+			return fmt.Errorf("line %d: %s", n, err)
+		}
+		if v.Gid == "" {
+			return fmt.Errorf("line %d: vertex has no gid", n)
+		}
+		if v.Label == "" {
+			return fmt.Errorf("line %d: vertex has no label", n)
+		}
+		if err := aql.ValidateLabel(v.Label); err != nil {
+			return fmt.Errorf("line %d: %s", n, err)
+		}
+		if err := aql.ValidateData(v.Data); err != nil {
+			return fmt.Errorf("line %d: %s", n, err)
+		}
+		if len(batch) == 0 {
+			first = n
+		}
+		batch = append(batch, v)
+		last = n
+		if len(batch) >= BatchSize {
+			return flush(n)
+		}
+		return nil
+	})
+	if err == nil {
+		err = flush(last)
+	}
+	return count, err
+}
+
+// LoadEdgesJSONL reads newline delimited JSON edges from `r` and adds them to
+// `graph` in batches of BatchSize. Edges without a gid are given a new one.
+// It returns the number of edges loaded. Loading stops at the first invalid
+// record or failed batch, and the error gives the line number of the record,
+// or the line range of the batch
+func (ma *Arachne) LoadEdgesJSONL(graph string, r io.Reader) (int, error) {
+	if err := ma.requireGraph("LoadEdgesJSONL", graph); err != nil {
+		return 0, err
+	}
+	g := ma.Graph(graph).(*Graph)
+	umarsh := jsonpb.Unmarshaler{AllowUnknownFields: true}
+	count := 0
+	first := 0
+	batch := make([]*aql.Edge, 0, BatchSize)
+	flush := func(last int) error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := g.SetEdge(batch); err != nil {
+			return fmt.Errorf("lines %d-%d: %s", first, last, err)
+		}
+		count += len(batch)
+		batch = batch[:0]
+		return nil
+	}
+	last := 0
+	err := readJSONL(r, func(n int, line []byte) error {
+		e := &aql.Edge{}
+		if err := umarsh.Unmarshal(bytes.NewReader(line), e); err != nil {
+			return fmt.Errorf("line %d: %s", n, err)
+		}
+		if e.From == "" || e.To == "" {
+			return fmt.Errorf("line %d: edge must have a from and to", n)
+		}
+		if e.Label == "" {
+			return fmt.Errorf("line %d: edge has no label", n)
+		}
+		if err := aql.ValidateLabel(e.Label); err != nil {
+			return fmt.Errorf("line %d: %s", n, err)
+		}
+		if err := aql.ValidateData(e.Data); err != nil {
+			return fmt.Errorf("line %d: %s", n, err)
+		}
+		if len(batch) == 0 {
+			first = n
+		}
+		batch = append(batch, e)
+		last = n
+		if len(batch) >= BatchSize {
+			return flush(n)
+		}
+		return nil
+	})
+	if err == nil {
+		err = flush(last)
+	}
+	return count, err
+}
@@ -0,0 +1,38 @@
+package mongo
+
+import (
+	"strings"
+	"testing"
+)
+
+// the invalid rows fail validation before the first batch is sent, so no
+// server is needed
+func TestLoadVerticesJSONLInvalid(t *testing.T) {
+	ma := cachedArachne("test")
+	tests := map[string]string{
+		`{"gid": "1", "label": "Person"}` + "\n" + `{"gid": "2"}`:                "line 2: vertex has no label",
+		`{"gid": "1", "label": "Person"}` + "\n\n" + `{"gid": "2", "label": ""}`: "line 3: vertex has no label",
+		`{"label": "Person"}`:              "line 1: vertex has no gid",
+		`{"gid": "1", "label": "$Person"}`: "line 1: invalid label",
+	}
+	for input, msg := range tests {
+		n, err := ma.LoadVerticesJSONL("test", strings.NewReader(input))
+		if n != 0 || err == nil || !strings.HasPrefix(err.Error(), msg) {
+			t.Errorf("expected error %q, got %d loaded and %v", msg, n, err)
+		}
+	}
+}
+
+func TestLoadEdgesJSONLInvalid(t *testing.T) {
+	ma := cachedArachne("test")
+	tests := map[string]string{
+		`{"from": "1", "to": "2", "label": "knows"}` + "\n" + `{"from": "1", "to": "2"}`: "line 2: edge has no label",
+		`{"from": "1", "label": "knows"}`:                                                "line 1: edge must have a from and to",
+	}
+	for input, msg := range tests {
+		n, err := ma.LoadEdgesJSONL("test", strings.NewReader(input))
+		if n != 0 || err == nil || !strings.HasPrefix(err.Error(), msg) {
+			t.Errorf("expected error %q, got %d loaded and %v", msg, n, err)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package mongo
+
+import (
+	"log"
+)
+
+// Logger receives the messages logged by the mongo driver. The arguments
+// follow the fmt.Printf conventions
+type Logger interface {
+	Debug(format string, args ...interface{})
+	Info(format string, args ...interface{})
+	Warn(format string, args ...interface{})
+	Error(format string, args ...interface{})
+}
+
+// stdLogger sends every message to the standard library logger
+type stdLogger struct{}
+
+func (stdLogger) Debug(format string, args ...interface{}) {
+	log.Printf(format, args...)
+}
+
+func (stdLogger) Info(format string, args ...interface{}) {
+	log.Printf(format, args...)
+}
+
+func (stdLogger) Warn(format string, args ...interface{}) {
+	log.Printf(format, args...)
+}
+
+func (stdLogger) Error(format string, args ...interface{}) {
+	log.Printf(format, args...)
+}
+
+// logger returns the configured Logger, or the standard logger if none is set
+func (ma *Arachne) logger() Logger {
+	if ma.conf.Logger != nil {
+		return ma.conf.Logger
+	}
+	return stdLogger{}
+}
@@ -0,0 +1,110 @@
+package mongo
+
+import (
+	"fmt"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// HasEdge returns true if `graph` has an edge with label `label` going from
+// vertex `from` to vertex `to`. An empty label matches edges of any label.
+// Edges stored in bundles are included. The lookup goes to the primary, so
+// edges that were just written are seen. An error is returned if the graph
+// doesn't exist
+func (ma *Arachne) HasEdge(graph, from, to, label string) (bool, error) {
+	if err := ma.requireGraph("HasEdge", graph); err != nil {
+		return false, err
+	}
+
+	query := bson.M{
+		fieldSrc: from,
+		"$or": []bson.M{
+			{fieldDst: to},
+			{fieldBundle + "." + to: bson.M{"$exists": true}},
+		},
+	}
+	if label != "" {
+		query[fieldLabel] = label
+	}
+	meta := ma.metaSession()
+	defer meta.Close()
+	edges := meta.DB(ma.database).C(ma.edgeCollectionName(graph))
+	n, err := edges.Find(query).Limit(1).Count()
+	if err != nil {
+		return false, wrapError("HasEdge", graph, err)
+	}
+	return n > 0, nil
+}
+
+// VerticesExist reports, for each of `ids`, whether a vertex with that id is
+// present in `graph`, reading from the primary like HasEdge. An error is
+// returned if the graph doesn't exist
+func (ma *Arachne) VerticesExist(graph string, ids []string) (map[string]bool, error) {
+	if err := ma.requireGraph("VerticesExist", graph); err != nil {
+		return nil, err
+	}
+
+	meta := ma.metaSession()
+	defer meta.Close()
+	vertices := meta.DB(ma.database).C(ma.vertexCollectionName(graph))
+	out := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		out[id] = false
+	}
+	for start := 0; start < len(ids); start += BatchSize {
+		end := start + BatchSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+		iter := vertices.Find(bson.M{"_id": bson.M{"$in": ids[start:end]}}).Select(bson.M{"_id": 1}).Iter()
+		result := map[string]interface{}{}
+		for iter.Next(&result) {
+			if id, ok := result["_id"].(string); ok {
+				out[id] = true
+			}
+		}
+		if err := iter.Close(); err != nil {
+			return nil, wrapError("VerticesExist", graph, err)
+		}
+	}
+	return out, nil
+}
+
+// DistinctValues returns the distinct values of data field `field` across
+// the vertices, if `element` is "vertex", or edges, if it is "edge", of
+// `graph`. Elements without the field are skipped. If `limit` is positive no
+// more than `limit` values are returned
+func (ma *Arachne) DistinctValues(graph, element, field string, limit int) ([]interface{}, error) {
+	if err := ma.requireGraph("DistinctValues", graph); err != nil {
+		return nil, err
+	}
+	var c *mgo.Collection
+	switch element {
+	case "vertex":
+		c = ma.getVertexReadCollection(graph)
+	case "edge":
+		c = ma.getEdgeReadCollection(graph)
+	default:
+		return nil, fmt.Errorf("Unknown element type %s: expected vertex or edge", element)
+	}
+
+	path := dataFieldPath(field)
+	pipe := []bson.M{
+		{"$match": bson.M{path: bson.M{"$exists": true}}},
+		{"$group": bson.M{"_id": "$" + path}},
+	}
+	if limit > 0 {
+		pipe = append(pipe, bson.M{"$limit": limit})
+	}
+	iter := c.Pipe(pipe).Iter()
+	out := []interface{}{}
+	result := bson.M{}
+	for iter.Next(&result) {
+		out = append(out, convertBSON(result["_id"]))
+		result = bson.M{}
+	}
+	if err := iter.Close(); err != nil {
+		return nil, wrapError("DistinctValues", graph, err)
+	}
+	return out, nil
+}
@@ -0,0 +1,24 @@
+package mongo
+
+import (
+	"time"
+)
+
+// Metrics receives the duration and outcome of the driver's operations, so
+// they can be exported to a monitoring system such as Prometheus
+type Metrics interface {
+	ObserveOp(name string, dur time.Duration, err error)
+}
+
+// observe reports the operation `name`, started at `start`, to the configured
+// Metrics. It is meant to be deferred with a pointer to the named error result
+func (ma *Arachne) observe(name string, start time.Time, err *error) {
+	if ma.conf.Metrics == nil {
+		return
+	}
+	var e error
+	if err != nil {
+		e = *err
+	}
+	ma.conf.Metrics.ObserveOp(name, time.Since(start), e)
+}
@@ -1,6 +1,7 @@
 package mongo
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"github.com/bmeg/arachne/aql"
@@ -8,65 +9,254 @@ import (
 	"github.com/bmeg/arachne/timestamp"
 	"io"
 	"strings"
+	"sync"
+	"time"
 	//"github.com/bmeg/golib/timing"
 	"gopkg.in/mgo.v2"
 	"gopkg.in/mgo.v2/bson"
-	"log"
 )
 
 // NewArachne creates a new ArachneInterface using the given
-// mongo server url and database name
-func NewArachne(url string, database string) gdbi.ArachneInterface {
+// mongo configuration
+func NewArachne(conf Config) (gdbi.ArachneInterface, error) {
+	dialInfo, err := conf.dialInfo()
+	if err != nil {
+		return nil, err
+	}
+	readMode, err := conf.readMode()
+	if err != nil {
+		return nil, err
+	}
 	ts := timestamp.NewTimestamp()
-	a := &Arachne{url: url, database: database, ts: &ts}
+	a := &Arachne{database: dialInfo.Database, conf: conf, dialInfo: dialInfo, readMode: readMode, ts: &ts}
+	session, err := a.newSession()
+	if err != nil {
+		return nil, err
+	}
+	a.setSession(session)
+	a.loadTimestamps()
 	for _, i := range a.GetGraphs() {
-		a.ts.Touch(i)
+		if a.ts.Get(i) == "" {
+			a.ts.Touch(i)
+		}
 	}
-	return a
+	return a, nil
 }
 
 // Arachne is the base driver that manages multiple graphs in mongo
 type Arachne struct {
-	url      string
 	database string
-	session  *mgo.Session
-	ts       *timestamp.Timestamp
+	conf     Config
+	dialInfo *mgo.DialInfo
+	readMode mgo.Mode
+	// session is used for writes and always talks to the primary, while
+	// readSession follows the configured read preference. Both are guarded by
+	// lock, use getSession and getReadSession to access them
+	lock        sync.RWMutex
+	session     *mgo.Session
+	readSession *mgo.Session
+	ts          *timestamp.Timestamp
+	graphCache  graphCache
 }
 
-func (ma *Arachne) newSession() *mgo.Session {
-	session, err := mgo.Dial(ma.url)
+func (ma *Arachne) newSession() (*mgo.Session, error) {
+	session, err := mgo.DialWithInfo(ma.dialInfo)
 	if err != nil {
-		log.Printf("%s", err)
+		return nil, err
 	}
-	b, _ := session.BuildInfo()
-	if !b.VersionAtLeast(3, 2) {
-		log.Printf("Requires mongo 3.2 or later")
+	b, err := session.BuildInfo()
+	if err != nil {
 		session.Close()
-		return nil
+		return nil, err
+	}
+	minVersion, err := ma.conf.minServerVersion()
+	if err != nil {
+		session.Close()
+		return nil, err
+	}
+	if !b.VersionAtLeast(minVersion...) {
+		session.Close()
+		return nil, fmt.Errorf("Requires mongo %s or later, server is %s", formatVersion(minVersion), b.Version)
+	}
+	if !b.VersionAtLeast(TestedServerVersion...) {
+		ma.logger().Warn("Mongo %s is older than %s, the oldest version arachne is tested against", b.Version, formatVersion(TestedServerVersion))
+	}
+	session.SetSocketTimeout(ma.conf.socketTimeout())
+	session.SetSyncTimeout(ma.conf.syncTimeout())
+	if ma.conf.WriteConcern != nil {
+		session.SetSafe(ma.conf.WriteConcern.safe())
+	}
+	return session, nil
+}
+
+// setSession installs a new write session and derives the read session from
+// it. It must be called with the write lock held
+func (ma *Arachne) setSession(session *mgo.Session) {
+	ma.session = session
+	ma.readSession = nil
+	if session != nil {
+		ma.readSession = session.Copy()
+		ma.readSession.SetMode(ma.readMode, true)
+	}
+}
+
+// connect dials the server if there is no session yet
+func (ma *Arachne) connect() {
+	ma.lock.Lock()
+	defer ma.lock.Unlock()
+	if ma.session != nil {
+		return
+	}
+	session, err := ma.newSession()
+	if err != nil {
+		ma.logger().Error("%s", err)
+		return
+	}
+	ma.setSession(session)
+}
+
+// getSession returns the write session, connecting first if needed
+func (ma *Arachne) getSession() *mgo.Session {
+	ma.lock.RLock()
+	session := ma.session
+	ma.lock.RUnlock()
+	if session == nil {
+		ma.connect()
+		ma.lock.RLock()
+		session = ma.session
+		ma.lock.RUnlock()
 	}
 	return session
 }
 
-func (ma *Arachne) refresh() {
-	if ma.session == nil {
-		ma.session = ma.newSession()
-	} else {
-		ma.session.Refresh()
+// getReadSession returns the read session, connecting first if needed
+func (ma *Arachne) getReadSession() *mgo.Session {
+	ma.lock.RLock()
+	session := ma.readSession
+	ma.lock.RUnlock()
+	if session == nil {
+		ma.connect()
+		ma.lock.RLock()
+		session = ma.readSession
+		ma.lock.RUnlock()
 	}
+	return session
 }
 
-func (ma *Arachne) getVertexCollection(graph string) *mgo.Collection {
-	if ma.session == nil {
-		ma.refresh()
+// metaSession returns a copy of the write session that always waits for the
+// server to acknowledge writes, used for updates to the graphs collection.
+// The caller is responsible for closing it
+func (ma *Arachne) metaSession() *mgo.Session {
+	s := ma.getSession().Copy()
+	if s.Safe() == nil {
+		s.SetSafe(&mgo.Safe{})
 	}
-	return ma.session.DB(ma.database).C(fmt.Sprintf("%s_vertices", graph))
+	return s
 }
 
-func (ma *Arachne) getEdgeCollection(graph string) *mgo.Collection {
-	if ma.session == nil {
+// reconnect is called after attempt `n` of an operation failed with a
+// connection error. It waits for the retry backoff, then refreshes the
+// sessions. They are refreshed in place rather than replaced, so iterators
+// other goroutines hold remain usable, and mgo redials the servers as needed
+func (ma *Arachne) reconnect(n int) {
+	time.Sleep(ma.conf.retryBackoff(n))
+	ma.logger().Info("Refreshing Connection")
+	ma.refresh()
+	if session := ma.getSession(); session != nil {
+		if err := session.Ping(); err != nil {
+			ma.logger().Warn("Mongo server still unreachable: %s", err)
+		}
+	}
+}
+
+// checkReadError refreshes the sessions if a read failed with a connection
+// error, so that the following reads don't reuse a dead socket
+func (ma *Arachne) checkReadError(err error) {
+	if err != nil && err != mgo.ErrNotFound && isNetError(err) {
+		ma.logger().Info("Refreshing Connection")
 		ma.refresh()
 	}
-	return ma.session.DB(ma.database).C(fmt.Sprintf("%s_edges", graph))
+}
+
+// timestampsID is the id of the document in the timestamps collection that
+// holds the saved graph timestamps
+const timestampsID = "graphs"
+
+// loadTimestamps restores, then removes, the timestamps saved by the last Close
+func (ma *Arachne) loadTimestamps() {
+	meta := ma.metaSession()
+	defer meta.Close()
+	c := meta.DB(ma.database).C(ma.timestampsCollectionName())
+	doc := map[string]interface{}{}
+	if err := c.FindId(timestampsID).One(&doc); err != nil {
+		return
+	}
+	if data, ok := doc["data"].(string); ok {
+		if err := ma.ts.Load(strings.NewReader(data)); err != nil {
+			ma.logger().Warn("Unable to load saved timestamps: %s", err)
+		}
+	}
+	c.RemoveId(timestampsID)
+}
+
+// saveTimestamps stores the current graph timestamps so they survive a restart
+func (ma *Arachne) saveTimestamps() {
+	buf := &bytes.Buffer{}
+	if err := ma.ts.Save(buf); err != nil {
+		return
+	}
+	meta := ma.metaSession()
+	defer meta.Close()
+	c := meta.DB(ma.database).C(ma.timestampsCollectionName())
+	if _, err := c.UpsertId(timestampsID, bson.M{"data": buf.String()}); err != nil {
+		ma.logger().Warn("Unable to save timestamps: %s", err)
+	}
+}
+
+func (ma *Arachne) refresh() {
+	ma.lock.RLock()
+	session, readSession := ma.session, ma.readSession
+	ma.lock.RUnlock()
+	if session == nil {
+		ma.connect()
+		return
+	}
+	session.Refresh()
+	readSession.Refresh()
+}
+
+func (ma *Arachne) vertexCollectionName(graph string) string {
+	return fmt.Sprintf("%s%s_vertices", ma.conf.CollectionPrefix, graph)
+}
+
+func (ma *Arachne) edgeCollectionName(graph string) string {
+	return fmt.Sprintf("%s%s_edges", ma.conf.CollectionPrefix, graph)
+}
+
+// graphsCollectionName is the collection listing the graphs in this database
+func (ma *Arachne) graphsCollectionName() string {
+	return ma.conf.CollectionPrefix + "graphs"
+}
+
+// timestampsCollectionName is the collection the graph timestamps are saved to
+func (ma *Arachne) timestampsCollectionName() string {
+	return ma.conf.CollectionPrefix + "timestamps"
+}
+
+func (ma *Arachne) getVertexCollection(graph string) *mgo.Collection {
+	return ma.getSession().DB(ma.database).C(ma.vertexCollectionName(graph))
+}
+
+func (ma *Arachne) getEdgeCollection(graph string) *mgo.Collection {
+	return ma.getSession().DB(ma.database).C(ma.edgeCollectionName(graph))
+}
+
+func (ma *Arachne) getVertexReadCollection(graph string) *mgo.Collection {
+	return ma.getReadSession().DB(ma.database).C(ma.vertexCollectionName(graph))
+}
+
+func (ma *Arachne) getEdgeReadCollection(graph string) *mgo.Collection {
+	return ma.getReadSession().DB(ma.database).C(ma.edgeCollectionName(graph))
 }
 
 // Graph is the tnterface to a single graph
@@ -76,73 +266,426 @@ type Graph struct {
 	graph string
 }
 
-// AddGraph creates a new graph named `graph`
-func (ma *Arachne) AddGraph(graph string) error {
-	if ma.session == nil {
-		ma.refresh()
+// AddGraph creates a new graph named `graph`. ErrGraphExists is returned if
+// the graph already exists
+func (ma *Arachne) AddGraph(graph string) (err error) {
+	defer ma.observe("AddGraph", time.Now(), &err)
+	if err := aql.ValidateGraphName(graph); err != nil {
+		return invalidName("AddGraph", graph, err)
 	}
 
-	graphs := ma.session.DB(ma.database).C(fmt.Sprintf("graphs"))
-	graphs.Insert(map[string]string{"_id": graph})
+	meta := ma.metaSession()
+	defer meta.Close()
+	graphs := meta.DB(ma.database).C(ma.graphsCollectionName())
+	if err := graphs.Insert(map[string]string{"_id": graph}); err != nil {
+		if mgo.IsDup(err) {
+			return ErrGraphExists
+		}
+		return wrapError("AddGraph", graph, err)
+	}
 
+	ma.graphCache.invalidate()
+
+	if !ma.conf.DeferIndexes {
+		if err := ma.BuildIndexes(graph); err != nil {
+			// don't leave the graph registered without its indexes
+			graphs.RemoveId(graph)
+			ma.graphCache.invalidate()
+			return err
+		}
+	}
+
+	ma.ts.Touch(graph)
+	return nil
+}
+
+// BuildIndexes creates the default label, from and to indexes of `graph`,
+// along with the indexes listed in Config.VertexIndexes and
+// Config.EdgeIndexes. AddGraph calls it unless Config.DeferIndexes is set, in
+// which case it should be called once the graph has been loaded
+func (ma *Arachne) BuildIndexes(graph string) (err error) {
+	defer ma.observe("BuildIndexes", time.Now(), &err)
+	if err := ma.requireGraph("BuildIndexes", graph); err != nil {
+		return err
+	}
+
+	background := !ma.conf.ForegroundIndexes
 	//v := ma.db.C(fmt.Sprintf("%s_vertices", graph))
 	e := ma.getEdgeCollection(graph)
-	e.EnsureIndex(mgo.Index{Key: []string{"$hashed:from"}})
-	e.EnsureIndex(mgo.Index{Key: []string{"$hashed:to"}})
-	e.EnsureIndex(mgo.Index{Key: []string{"$hashed:label"}})
+	for _, k := range []string{"$hashed:from", "$hashed:to", "$hashed:label"} {
+		if err := e.EnsureIndex(mgo.Index{Key: []string{k}, Background: background}); err != nil {
+			return &Error{Op: "BuildIndexes", Graph: graph, Err: err, Detail: "Failed to create edge index " + k}
+		}
+	}
 
 	v := ma.getVertexCollection(graph)
-	v.EnsureIndex(mgo.Index{Key: []string{"$hashed:label"}})
+	if err := v.EnsureIndex(mgo.Index{Key: []string{"$hashed:label"}, Background: background}); err != nil {
+		return &Error{Op: "BuildIndexes", Graph: graph, Err: err, Detail: "Failed to create vertex index $hashed:label"}
+	}
 
-	ma.ts.Touch(graph)
+	if ma.conf.UniqueEdges {
+		if err := ma.enforceEdgeUniqueness(graph); err != nil {
+			return err
+		}
+	}
+
+	for _, i := range ma.conf.VertexIndexes {
+		if err := v.EnsureIndex(i.mgoIndex()); err != nil {
+			return &Error{Op: "BuildIndexes", Graph: graph, Err: err, Detail: fmt.Sprintf("Failed to create vertex index %s", i.Key)}
+		}
+	}
+	for _, i := range ma.conf.EdgeIndexes {
+		if err := e.EnsureIndex(i.mgoIndex()); err != nil {
+			return &Error{Op: "BuildIndexes", Graph: graph, Err: err, Detail: fmt.Sprintf("Failed to create edge index %s", i.Key)}
+		}
+	}
+	return nil
+}
+
+// EnforceEdgeUniqueness creates a unique index on the from, to and label of
+// the edges of `graph`, so there can be at most one edge of a label between
+// two vertices. Bundles are not covered by the index. Once it is in place,
+// SetEdge returns an error wrapping ErrDuplicateEdge for duplicate edges
+func (ma *Arachne) EnforceEdgeUniqueness(graph string) error {
+	if err := ma.requireGraph("EnforceEdgeUniqueness", graph); err != nil {
+		return err
+	}
+	return ma.enforceEdgeUniqueness(graph)
+}
+
+// enforceEdgeUniqueness is EnforceEdgeUniqueness for a graph known to exist
+func (ma *Arachne) enforceEdgeUniqueness(graph string) error {
+	// mgo.Index has no partial filter, so the index is created with the
+	// createIndexes command directly
+	index := bson.M{
+		"key":                     bson.D{{Name: fieldSrc, Value: 1}, {Name: fieldDst, Value: 1}, {Name: fieldLabel, Value: 1}},
+		"name":                    "unique_edges",
+		"unique":                  true,
+		"background":              !ma.conf.ForegroundIndexes,
+		"partialFilterExpression": bson.M{fieldDst: bson.M{"$exists": true}},
+	}
+	cmd := bson.D{
+		{Name: "createIndexes", Value: ma.edgeCollectionName(graph)},
+		{Name: "indexes", Value: []bson.M{index}},
+	}
+	meta := ma.metaSession()
+	defer meta.Close()
+	if err := meta.DB(ma.database).Run(cmd, nil); err != nil {
+		return wrapError("EnforceEdgeUniqueness", graph, err)
+	}
 	return nil
 }
 
+// AddVertexIndex creates an index on the vertex collection of an existing graph
+func (ma *Arachne) AddVertexIndex(graph string, index Index) error {
+	if err := ma.requireGraph("AddVertexIndex", graph); err != nil {
+		return err
+	}
+	return wrapError("AddVertexIndex", graph, ma.getVertexCollection(graph).EnsureIndex(index.mgoIndex()))
+}
+
+// AddEdgeIndex creates an index on the edge collection of an existing graph
+func (ma *Arachne) AddEdgeIndex(graph string, index Index) error {
+	if err := ma.requireGraph("AddEdgeIndex", graph); err != nil {
+		return err
+	}
+	return wrapError("AddEdgeIndex", graph, ma.getEdgeCollection(graph).EnsureIndex(index.mgoIndex()))
+}
+
+// dataFieldPath translates the name of a vertex/edge data field, such as
+// "symbol" or "$.symbol", into its path in the stored mongo document
+func dataFieldPath(field string) string {
+	return "data." + strings.TrimPrefix(field, "$.")
+}
+
+// EnsureDataIndex creates a background index on the vertex data field `field`
+// of `graph`, to speed up equality lookups on that field
+func (ma *Arachne) EnsureDataIndex(graph, field string) error {
+	if err := ma.requireGraph("EnsureDataIndex", graph); err != nil {
+		return err
+	}
+	index := Index{Key: []string{dataFieldPath(field)}, Background: true}
+	return wrapError("EnsureDataIndex", graph, ma.getVertexCollection(graph).EnsureIndex(index.mgoIndex()))
+}
+
+// EnableVertexTTL creates a TTL index so that vertices of `graph` are removed
+// by the server once the date in data field `dateField` is older than `ttl`.
+// The field must hold a BSON date, documents where it is missing or of
+// another type never expire. Removal is done by mongo's background TTL
+// monitor, which runs about once a minute, so expiry is not immediate
+func (ma *Arachne) EnableVertexTTL(graph, dateField string, ttl time.Duration) error {
+	if ttl < time.Second {
+		return fmt.Errorf("Invalid TTL %s: must be at least one second", ttl)
+	}
+	if err := ma.requireGraph("EnableVertexTTL", graph); err != nil {
+		return err
+	}
+	index := mgo.Index{Key: []string{dataFieldPath(dateField)}, ExpireAfter: ttl, Background: true}
+	return wrapError("EnableVertexTTL", graph, ma.getVertexCollection(graph).EnsureIndex(index))
+}
+
+// DisableVertexTTL drops the TTL index created by EnableVertexTTL on data
+// field `dateField` of `graph`
+func (ma *Arachne) DisableVertexTTL(graph, dateField string) error {
+	if err := ma.requireGraph("DisableVertexTTL", graph); err != nil {
+		return err
+	}
+	return wrapError("DisableVertexTTL", graph, ma.getVertexCollection(graph).DropIndex(dataFieldPath(dateField)))
+}
+
+// ListIndexes returns the indexes currently defined on the vertex collection
+// of `graph`
+func (ma *Arachne) ListIndexes(graph string) ([]mgo.Index, error) {
+	if err := ma.requireGraph("ListIndexes", graph); err != nil {
+		return nil, err
+	}
+	indexes, err := ma.getVertexReadCollection(graph).Indexes()
+	if err != nil {
+		return nil, wrapError("ListIndexes", graph, err)
+	}
+	return indexes, nil
+}
+
+// ListDataIndexes returns the vertex data fields of `graph` that have an
+// index. Like ListIndexes, it returns ErrGraphNotFound for a missing graph
+func (ma *Arachne) ListDataIndexes(graph string) ([]string, error) {
+	indexes, err := ma.ListIndexes(graph)
+	if err != nil {
+		return nil, err
+	}
+	out := []string{}
+	for _, i := range indexes {
+		for _, k := range i.Key {
+			// strip the "-" and "$type:" prefixes used by mgo index keys
+			k = strings.TrimPrefix(k, "-")
+			if j := strings.Index(k, ":"); j >= 0 {
+				k = k[j+1:]
+			}
+			if strings.HasPrefix(k, "data.") {
+				out = append(out, strings.TrimPrefix(k, "data."))
+			}
+		}
+	}
+	return out, nil
+}
+
+// Ping checks that the mongo server can be reached, returning an error if
+// the server does not respond before `ctx` is done
+func (ma *Arachne) Ping(ctx context.Context) error {
+	session := ma.getSession()
+	if session == nil {
+		return fmt.Errorf("No connection to mongo server")
+	}
+	session = session.Copy()
+	done := make(chan error, 1)
+	go func() {
+		defer session.Close()
+		done <- session.Ping()
+	}()
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("Mongo ping failed: %s", ctx.Err())
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("Mongo ping failed: %s", err)
+		}
+		return nil
+	}
+}
+
+// TimestampVertices returns the time the vertices of `graph` were last modified
+func (ma *Arachne) TimestampVertices(graph string) string {
+	return ma.ts.GetElement(graph, timestamp.ElementVertex)
+}
+
+// TimestampEdges returns the time the edges of `graph` were last modified
+func (ma *Arachne) TimestampEdges(graph string) string {
+	return ma.ts.GetElement(graph, timestamp.ElementEdge)
+}
+
+// Watch returns a channel that receives an event each time `graph` is
+// modified. Pass the channel to Unwatch to stop receiving events
+func (ma *Arachne) Watch(graph string) <-chan struct{} {
+	return ma.ts.Watch(graph)
+}
+
+// Unwatch stops the events sent to a channel returned by Watch
+func (ma *Arachne) Unwatch(graph string, c <-chan struct{}) {
+	ma.ts.Unwatch(graph, c)
+}
+
 // Close the connection
 func (ma *Arachne) Close() {
-	ma.session.Close()
-	ma.session = nil
+	ma.lock.RLock()
+	connected := ma.session != nil
+	ma.lock.RUnlock()
+	if connected {
+		ma.saveTimestamps()
+	}
+	ma.lock.Lock()
+	defer ma.lock.Unlock()
+	if ma.session != nil {
+		ma.readSession.Close()
+		ma.session.Close()
+	}
+	ma.setSession(nil)
 }
 
-// DeleteGraph deletes `graph`
-func (ma *Arachne) DeleteGraph(graph string) error {
-	if ma.session == nil {
-		ma.refresh()
-	}
+// DeleteGraph deletes `graph`. The graph stays listed if its collections
+// can't be dropped, so the delete can be retried
+func (ma *Arachne) DeleteGraph(graph string) (err error) {
+	defer ma.observe("DeleteGraph", time.Now(), &err)
 
-	g := ma.session.DB(ma.database).C("graphs")
-	v := ma.getVertexCollection(graph)
-	e := ma.getEdgeCollection(graph)
-	v.DropCollection()
-	e.DropCollection()
-	g.RemoveId(graph)
+	meta := ma.metaSession()
+	defer meta.Close()
+	g := meta.DB(ma.database).C(ma.graphsCollectionName())
+	// collections that were never written to don't exist, that isn't an error
+	if err := ma.getVertexCollection(graph).DropCollection(); err != nil && !isNamespaceNotFound(err) {
+		return &Error{Op: "DeleteGraph", Graph: graph, Err: err, Detail: "Failed to drop vertex collection"}
+	}
+	if err := ma.getEdgeCollection(graph).DropCollection(); err != nil && !isNamespaceNotFound(err) {
+		return &Error{Op: "DeleteGraph", Graph: graph, Err: err, Detail: "Failed to drop edge collection"}
+	}
+	defer ma.graphCache.invalidate()
+	if err := g.RemoveId(graph); err != nil && err != mgo.ErrNotFound {
+		return wrapError("DeleteGraph", graph, err)
+	}
 	ma.ts.Touch(graph)
 	return nil
 }
 
+// RenameGraph renames graph `oldGraph` to `newGraph`, moving its vertex and
+// edge collections on the server. It fails if `newGraph` already exists
+func (ma *Arachne) RenameGraph(oldGraph, newGraph string) (err error) {
+	defer ma.observe("RenameGraph", time.Now(), &err)
+	if err := aql.ValidateGraphName(newGraph); err != nil {
+		return invalidName("RenameGraph", newGraph, err)
+	}
+
+	if err := ma.requireGraph("RenameGraph", oldGraph); err != nil {
+		return err
+	}
+	if exists, err := ma.GraphExists(newGraph); err != nil {
+		return wrapError("RenameGraph", newGraph, err)
+	} else if exists {
+		return ErrGraphExists
+	}
+
+	meta := ma.metaSession()
+	defer meta.Close()
+	rename := func(from, to string) error {
+		cmd := bson.D{
+			{Name: "renameCollection", Value: ma.database + "." + from},
+			{Name: "to", Value: ma.database + "." + to},
+		}
+		return meta.DB("admin").Run(cmd, nil)
+	}
+	if err := rename(ma.vertexCollectionName(oldGraph), ma.vertexCollectionName(newGraph)); err != nil {
+		return &Error{Op: "RenameGraph", Graph: oldGraph, Err: err, Detail: "Failed to rename vertex collection"}
+	}
+	if err := rename(ma.edgeCollectionName(oldGraph), ma.edgeCollectionName(newGraph)); err != nil {
+		rename(ma.vertexCollectionName(newGraph), ma.vertexCollectionName(oldGraph))
+		return &Error{Op: "RenameGraph", Graph: oldGraph, Err: err, Detail: "Failed to rename edge collection"}
+	}
+
+	g := meta.DB(ma.database).C(ma.graphsCollectionName())
+	defer ma.graphCache.invalidate()
+	if err := g.Insert(map[string]string{"_id": newGraph}); err != nil {
+		if mgo.IsDup(err) {
+			return ErrGraphExists
+		}
+		return wrapError("RenameGraph", newGraph, err)
+	}
+	if err := g.RemoveId(oldGraph); err != nil {
+		return wrapError("RenameGraph", oldGraph, err)
+	}
+	ma.ts.Touch(oldGraph)
+	ma.ts.Touch(newGraph)
+	return nil
+}
+
+// CopyGraph creates graph `dst` as a copy of graph `src`. The documents are
+// copied on the server using an aggregation $out stage, so no data is
+// streamed through the client. It fails if `dst` already exists
+func (ma *Arachne) CopyGraph(src, dst string) (err error) {
+	defer ma.observe("CopyGraph", time.Now(), &err)
+	if err := aql.ValidateGraphName(dst); err != nil {
+		return invalidName("CopyGraph", dst, err)
+	}
+
+	if err := ma.requireGraph("CopyGraph", src); err != nil {
+		return err
+	}
+	if exists, err := ma.GraphExists(dst); err != nil {
+		return wrapError("CopyGraph", dst, err)
+	} else if exists {
+		return ErrGraphExists
+	}
+
+	if err := ma.AddGraph(dst); err != nil {
+		return err
+	}
+	copyCollection := func(from *mgo.Collection, to string) error {
+		pipe := []bson.M{{"$match": bson.M{}}, {"$out": to}}
+		return from.Pipe(pipe).Iter().Close()
+	}
+	if err := copyCollection(ma.getVertexCollection(src), ma.vertexCollectionName(dst)); err != nil {
+		ma.DeleteGraph(dst)
+		return &Error{Op: "CopyGraph", Graph: src, Err: err, Detail: "Failed to copy vertices"}
+	}
+	if err := copyCollection(ma.getEdgeCollection(src), ma.edgeCollectionName(dst)); err != nil {
+		ma.DeleteGraph(dst)
+		return &Error{Op: "CopyGraph", Graph: src, Err: err, Detail: "Failed to copy edges"}
+	}
+	ma.ts.Touch(dst)
+	return nil
+}
+
 // GetGraphs lists the graphs managed by this driver
 func (ma *Arachne) GetGraphs() []string {
-	if ma.session == nil {
-		ma.refresh()
+	out, err := ma.GetGraphsContext(context.Background())
+	if err != nil {
+		ma.logger().Error("Error: %s", err)
 	}
+	ma.logger().Debug("Graphs: %s %s", ma.database, out)
+	return out
+}
 
-	out := make([]string, 0, 100)
-	g := ma.session.DB(ma.database).C("graphs")
+// GetGraphsContext lists the graphs managed by this driver, stopping early
+// if `ctx` is cancelled. The graphs collection is read on the primary
+func (ma *Arachne) GetGraphsContext(ctx context.Context) (out []string, err error) {
+	if ma.conf.GraphCacheTTL > 0 {
+		if graphs, ok := ma.graphCache.get(); ok {
+			return graphs, nil
+		}
+	}
+	defer ma.observe("GetGraphs", time.Now(), &err)
+
+	gen := ma.graphCache.generation()
+	out = make([]string, 0, 100)
+	meta := ma.metaSession()
+	defer meta.Close()
+	g := meta.DB(ma.database).C(ma.graphsCollectionName())
 
 	iter := g.Find(nil).Iter()
 	defer iter.Close()
-	if err := iter.Err(); err != nil {
-		log.Printf("Error: %s", err)
-	}
 	result := map[string]interface{}{}
 	for iter.Next(&result) {
+		select {
+		case <-ctx.Done():
+			return out, ctx.Err()
+		default:
+		}
 		out = append(out, result["_id"].(string))
 	}
 	if err := iter.Err(); err != nil {
-		log.Printf("Error: %s", err)
+		ma.checkReadError(err)
+		return out, wrapError("GetGraphs", "", err)
 	}
-	log.Printf("Graphs: %s %s", ma.database, out)
-	return out
+	if ma.conf.GraphCacheTTL > 0 {
+		ma.graphCache.set(out, ma.conf.GraphCacheTTL, gen)
+	}
+	return out, nil
 }
 
 // Graph obtains the gdbi.DBI for a particular graph
@@ -154,6 +697,53 @@ func (ma *Arachne) Graph(graph string) gdbi.DBI {
 	}
 }
 
+// GetGraph obtains the gdbi.DBI for a particular graph, like Graph, but
+// returns an error wrapping ErrGraphNotFound if the graph doesn't exist
+func (ma *Arachne) GetGraph(graph string) (gdbi.DBI, error) {
+	if err := ma.requireGraph("GetGraph", graph); err != nil {
+		return nil, err
+	}
+	return ma.Graph(graph), nil
+}
+
+// GraphExists returns true if `graph` exists, using a single lookup on the
+// graphs collection. The lookup goes to the primary, so a graph that was just
+// added is always seen
+func (ma *Arachne) GraphExists(graph string) (bool, error) {
+	if ma.conf.GraphCacheTTL > 0 {
+		if graphs, ok := ma.graphCache.get(); ok {
+			for _, g := range graphs {
+				if g == graph {
+					return true, nil
+				}
+			}
+			return false, nil
+		}
+	}
+	meta := ma.metaSession()
+	defer meta.Close()
+	g := meta.DB(ma.database).C(ma.graphsCollectionName())
+	n, err := g.FindId(graph).Count()
+	if err != nil {
+		ma.checkReadError(err)
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// requireGraph returns an error wrapping ErrGraphNotFound if `graph` doesn't
+// exist, or the error from the lookup
+func (ma *Arachne) requireGraph(op, graph string) error {
+	exists, err := ma.GraphExists(graph)
+	if err != nil {
+		return wrapError(op, graph, err)
+	}
+	if !exists {
+		return graphNotFound(op, graph)
+	}
+	return nil
+}
+
 // Query creates a QueryInterface for Graph graph
 func (ma *Arachne) Query(graph string) gdbi.QueryInterface {
 	return ma.Graph(graph).Query()
@@ -168,8 +758,8 @@ func (mg *Graph) Query() gdbi.QueryInterface {
 func (mg *Graph) GetEdge(id string, loadProp bool) *aql.Edge {
 	//log.Printf("GetEdge: %s", id)
 	d := map[string]interface{}{}
-	q := mg.ar.getEdgeCollection(mg.graph).FindId(id)
-	q.One(d)
+	q := mg.ar.getEdgeReadCollection(mg.graph).FindId(id)
+	mg.ar.checkReadError(q.One(d))
 	v := UnpackEdge(d)
 	return &v
 }
@@ -183,27 +773,33 @@ func (mg *Graph) GetTimestamp() string {
 func (mg *Graph) GetVertex(key string, load bool) *aql.Vertex {
 	//log.Printf("GetVertex: %s", key)
 	d := map[string]interface{}{}
-	vCol := mg.ar.getVertexCollection(mg.graph)
+	vCol := mg.ar.getVertexReadCollection(mg.graph)
 	q := vCol.Find(map[string]interface{}{"_id": key}).Limit(1)
 	if !load {
 		q = q.Select(map[string]interface{}{"_id": 1, "label": 1})
 	}
 	err := q.One(d)
 	if err != nil {
+		mg.ar.checkReadError(err)
 		return nil
 	}
 	v := UnpackVertex(d)
 	return &v
 }
 
-// MaxRetries is the number of times driver will reconnect on connection failure
-// TODO, move to per instance config, rather then global
+// MaxRetries is the number of times driver will reconnect on connection
+// failure, unless Config.MaxRetries is set
 var MaxRetries = 3
 
 func isNetError(e error) bool {
 	if e == io.EOF {
 		return true
 	}
+	for _, msg := range []string{"no reachable servers", "connection reset", "broken pipe"} {
+		if strings.Contains(e.Error(), msg) {
+			return true
+		}
+	}
 	if b, ok := e.(*mgo.BulkError); ok {
 		for _, c := range b.Cases() {
 			if c.Err == io.EOF {
@@ -220,30 +816,113 @@ func isNetError(e error) bool {
 // SetVertex adds an edge to the graph, if it already exists
 // in the graph, it is replaced
 func (mg *Graph) SetVertex(vertexArray []*aql.Vertex) error {
-	vCol := mg.ar.getVertexCollection(mg.graph)
+	for _, vertex := range vertexArray {
+		if err := aql.ValidateLabel(vertex.Label); err != nil {
+			return err
+		}
+		if err := aql.ValidateData(vertex.Data); err != nil {
+			return err
+		}
+	}
 	var err error
-	for i := 0; i < MaxRetries; i++ {
+	for i := 0; i < mg.ar.conf.maxRetries(); i++ {
+		vCol := mg.ar.getVertexCollection(mg.graph)
 		bulk := vCol.Bulk()
 		for _, vertex := range vertexArray {
 			bulk.Upsert(bson.M{"_id": vertex.Gid}, PackVertex(*vertex))
 		}
 		_, err = bulk.Run()
 		if err == nil || !isNetError(err) {
-			mg.ts.Touch(mg.graph)
+			mg.ts.Touch(mg.graph, timestamp.ElementVertex)
 			return err
 		}
-		log.Printf("Refreshing Connection")
-		mg.ar.refresh()
+		mg.ar.reconnect(i)
 	}
 	return err
 }
 
+// AddVertices upserts `vertices` into the graph by gid, sending them to the
+// server in bulk requests of BatchSize. It returns the number of vertices that
+// were inserted and the number of existing vertices that were modified
+func (mg *Graph) AddVertices(vertices []*aql.Vertex) (inserted int, modified int, err error) {
+	for _, vertex := range vertices {
+		if err := aql.ValidateLabel(vertex.Label); err != nil {
+			return 0, 0, err
+		}
+		if err := aql.ValidateData(vertex.Data); err != nil {
+			return 0, 0, err
+		}
+	}
+	defer mg.ts.Touch(mg.graph, timestamp.ElementVertex)
+	for start := 0; start < len(vertices); start += BatchSize {
+		end := start + BatchSize
+		if end > len(vertices) {
+			end = len(vertices)
+		}
+		ids := map[string]bool{}
+		for _, vertex := range vertices[start:end] {
+			ids[vertex.Gid] = true
+		}
+		var res *mgo.BulkResult
+		// the existing vertices are counted once, before the first attempt,
+		// so a retried batch doesn't count its own partial writes
+		existing, counted := 0, false
+		for i := 0; i < mg.ar.conf.maxRetries(); i++ {
+			if !counted {
+				if existing, err = mg.countExisting(ids); err != nil {
+					if !isNetError(err) {
+						break
+					}
+					mg.ar.reconnect(i)
+					continue
+				}
+				counted = true
+			}
+			bulk := mg.ar.getVertexCollection(mg.graph).Bulk()
+			bulk.Unordered()
+			for _, vertex := range vertices[start:end] {
+				bulk.Upsert(bson.M{"_id": vertex.Gid}, PackVertex(*vertex))
+			}
+			res, err = bulk.Run()
+			if err == nil || !isNetError(err) {
+				break
+			}
+			mg.ar.reconnect(i)
+		}
+		if err != nil {
+			return inserted, modified, wrapError("AddVertices", mg.graph, err)
+		}
+		inserted += len(ids) - existing
+		modified += res.Modified
+	}
+	return inserted, modified, nil
+}
+
+// countExisting returns how many of the vertex ids in `ids` are already
+// stored, reading from the primary so vertices just written are seen
+func (mg *Graph) countExisting(ids map[string]bool) (int, error) {
+	idList := make([]string, 0, len(ids))
+	for id := range ids {
+		idList = append(idList, id)
+	}
+	vCol := mg.ar.getVertexCollection(mg.graph)
+	return vCol.Find(bson.M{"_id": bson.M{"$in": idList}}).Count()
+}
+
 // SetEdge adds an edge to the graph, if the id is not "" and in already exists
 // in the graph, it is replaced
 func (mg *Graph) SetEdge(edgeArray []*aql.Edge) error {
-	eCol := mg.ar.getEdgeCollection(mg.graph)
+	for _, edge := range edgeArray {
+		if err := aql.ValidateLabel(edge.Label); err != nil {
+			return err
+		}
+		if err := aql.ValidateData(edge.Data); err != nil {
+			return err
+		}
+	}
 	var err error
-	for i := 0; i < MaxRetries; i++ {
+	for i := 0; i < mg.ar.conf.maxRetries(); i++ {
+		eCol := mg.ar.getEdgeCollection(mg.graph)
 		bulk := eCol.Bulk()
 		for _, edge := range edgeArray {
 			if edge.Gid != "" {
@@ -253,34 +932,36 @@ func (mg *Graph) SetEdge(edgeArray []*aql.Edge) error {
 				bulk.Insert(PackEdge(*edge))
 			}
 		}
-		_, err := bulk.Run()
+		_, err = bulk.Run()
 		if err == nil || !isNetError(err) {
-			mg.ts.Touch(mg.graph)
+			mg.ts.Touch(mg.graph, timestamp.ElementEdge)
+			if mgo.IsDup(err) {
+				return &Error{Op: "SetEdge", Graph: mg.graph, Err: ErrDuplicateEdge}
+			}
 			return err
 		}
-		log.Printf("Refreshing Connection")
-		mg.ar.refresh()
+		mg.ar.reconnect(i)
 	}
 	return err
 }
 
 // DelVertex deletes vertex with id `key`
 func (mg *Graph) DelVertex(key string) error {
-	mg.ts.Touch(mg.graph)
+	mg.ts.Touch(mg.graph, timestamp.ElementVertex)
 	vCol := mg.ar.getVertexCollection(mg.graph)
 	return vCol.RemoveId(key)
 }
 
 // DelEdge deletes edge with id `key`
 func (mg *Graph) DelEdge(key string) error {
-	mg.ts.Touch(mg.graph)
+	mg.ts.Touch(mg.graph, timestamp.ElementEdge)
 	eCol := mg.ar.getEdgeCollection(mg.graph)
 	return eCol.RemoveId(key)
 }
 
 // GetVertexList produces a channel of all edges in the graph
 func (mg *Graph) GetVertexList(ctx context.Context, load bool) chan aql.Vertex {
-	vCol := mg.ar.getVertexCollection(mg.graph)
+	vCol := mg.ar.getVertexReadCollection(mg.graph)
 	o := make(chan aql.Vertex, 100)
 	go func() {
 		defer close(o)
@@ -294,7 +975,11 @@ func (mg *Graph) GetVertexList(ctx context.Context, load bool) chan aql.Vertex {
 			default:
 			}
 			v := UnpackVertex(result)
-			o <- v
+			select {
+			case <-ctx.Done():
+				return
+			case o <- v:
+			}
 		}
 	}()
 	return o
@@ -303,7 +988,7 @@ func (mg *Graph) GetVertexList(ctx context.Context, load bool) chan aql.Vertex {
 // GetEdgeList produces a channel of all edges in the graph
 func (mg *Graph) GetEdgeList(ctx context.Context, loadProp bool) chan aql.Edge {
 	o := make(chan aql.Edge, 100)
-	eCol := mg.ar.getEdgeCollection(mg.graph)
+	eCol := mg.ar.getEdgeReadCollection(mg.graph)
 	go func() {
 		defer close(o)
 		iter := eCol.Find(nil).Iter()
@@ -317,12 +1002,20 @@ func (mg *Graph) GetEdgeList(ctx context.Context, loadProp bool) chan aql.Edge {
 			}
 			if _, ok := result[fieldDst]; ok {
 				e := UnpackEdge(result)
-				o <- e
+				select {
+				case <-ctx.Done():
+					return
+				case o <- e:
+				}
 			} else if _, ok := result[fieldBundle]; ok {
 				bundle := UnpackBundle(result)
 				for k, v := range bundle.Bundle {
 					e := aql.Edge{Gid: bundle.Gid, Label: bundle.Label, From: bundle.From, To: k, Data: v}
-					o <- e
+					select {
+					case <-ctx.Done():
+						return
+					case o <- e:
+					}
 				}
 			}
 		}
@@ -354,7 +1047,7 @@ func (mg *Graph) GetVertexChannel(ids chan gdbi.ElementLookup, load bool) chan g
 	out := make(chan gdbi.ElementLookup, 100)
 	go func() {
 		defer close(out)
-		vCol := mg.ar.getVertexCollection(mg.graph)
+		vCol := mg.ar.getVertexReadCollection(mg.graph)
 		for batch := range batches {
 			//log.Printf("Getting Batch")
 			idBatch := make([]string, len(batch))
@@ -369,7 +1062,8 @@ func (mg *Graph) GetVertexChannel(ids chan gdbi.ElementLookup, load bool) chan g
 			}
 			iter := q.Iter()
 			if iter.Err() != nil {
-				log.Printf("batch err: %s", iter.Err())
+				mg.ar.logger().Error("batch err: %s", iter.Err())
+				mg.ar.checkReadError(iter.Err())
 			}
 			defer iter.Close()
 			chunk := map[string]*aql.Vertex{}
@@ -423,10 +1117,11 @@ func (mg *Graph) GetOutChannel(reqChan chan gdbi.ElementLookup, load bool, edgeL
 			if len(edgeLabels) > 0 {
 				query = append(query, bson.M{"$match": bson.M{fieldLabel: bson.M{"$in": edgeLabels}}})
 			}
-			vertCol := fmt.Sprintf("%s_vertices", mg.graph)
+			vertCol := mg.ar.vertexCollectionName(mg.graph)
 			query = append(query, bson.M{"$lookup": bson.M{"from": vertCol, "localField": "to", "foreignField": "_id", "as": "dst"}})
 
-			eCol := mg.ar.getEdgeCollection(mg.graph)
+			eCol := mg.ar.getEdgeReadCollection(mg.graph)
+			start := time.Now()
 			iter := eCol.Pipe(query).Iter()
 			defer iter.Close()
 			result := map[string]interface{}{}
@@ -437,7 +1132,7 @@ func (mg *Graph) GetOutChannel(reqChan chan gdbi.ElementLookup, load bool, edgeL
 					for k := range vMap {
 						bkeys = append(bkeys, k)
 					}
-					vCol := mg.ar.getVertexCollection(mg.graph)
+					vCol := mg.ar.getVertexReadCollection(mg.graph)
 					query := bson.M{"_id": bson.M{"$in": bkeys}}
 					q := vCol.Find(query)
 					vIter := q.Iter()
@@ -463,6 +1158,9 @@ func (mg *Graph) GetOutChannel(reqChan chan gdbi.ElementLookup, load bool, edgeL
 				}
 
 			}
+			err := iter.Err()
+			mg.ar.observe("GetOutChannel", start, &err)
+			mg.ar.checkReadError(err)
 		}
 	}()
 	return o
@@ -498,10 +1196,11 @@ func (mg *Graph) GetInChannel(reqChan chan gdbi.ElementLookup, load bool, edgeLa
 			if len(edgeLabels) > 0 {
 				query = append(query, bson.M{"$match": bson.M{fieldLabel: bson.M{"$in": edgeLabels}}})
 			}
-			vertCol := fmt.Sprintf("%s_vertices", mg.graph)
+			vertCol := mg.ar.vertexCollectionName(mg.graph)
 			query = append(query, bson.M{"$lookup": bson.M{"from": vertCol, "localField": "from", "foreignField": "_id", "as": "src"}})
 			//log.Printf("Doing Query %s", query)
-			eCol := mg.ar.getEdgeCollection(mg.graph)
+			eCol := mg.ar.getEdgeReadCollection(mg.graph)
+			start := time.Now()
 			iter := eCol.Pipe(query).Iter()
 			defer iter.Close()
 			result := map[string]interface{}{}
@@ -516,8 +1215,11 @@ func (mg *Graph) GetInChannel(reqChan chan gdbi.ElementLookup, load bool, edgeLa
 					}
 				}
 			}
-			if err := iter.Err(); err != nil {
-				log.Printf("Iteration Error %s", err)
+			err := iter.Err()
+			mg.ar.observe("GetInChannel", start, &err)
+			mg.ar.checkReadError(err)
+			if err != nil {
+				mg.ar.logger().Error("Iteration Error %s", err)
 			}
 		}
 	}()
@@ -554,13 +1256,14 @@ func (mg *Graph) GetOutEdgeChannel(reqChan chan gdbi.ElementLookup, load bool, e
 			if len(edgeLabels) > 0 {
 				query = append(query, bson.M{"$match": bson.M{fieldLabel: bson.M{"$in": edgeLabels}}})
 			}
-			eCol := mg.ar.getEdgeCollection(mg.graph)
+			eCol := mg.ar.getEdgeReadCollection(mg.graph)
+			start := time.Now()
 			iter := eCol.Pipe(query).Iter()
 			defer iter.Close()
 			result := map[string]interface{}{}
 			for iter.Next(&result) {
 				if _, ok := result["bundle"]; ok {
-					log.Printf("Bundle: %s", result)
+					mg.ar.logger().Debug("Bundle: %s", result)
 					bundle := UnpackBundle(result)
 					for k, v := range bundle.Bundle {
 						e := aql.Edge{Gid: bundle.Gid, Label: bundle.Label, From: bundle.From, To: k, Data: v}
@@ -579,6 +1282,9 @@ func (mg *Graph) GetOutEdgeChannel(reqChan chan gdbi.ElementLookup, load bool, e
 					}
 				}
 			}
+			err := iter.Err()
+			mg.ar.observe("GetOutEdgeChannel", start, &err)
+			mg.ar.checkReadError(err)
 		}
 	}()
 	return o
@@ -614,7 +1320,8 @@ func (mg *Graph) GetInEdgeChannel(reqChan chan gdbi.ElementLookup, load bool, ed
 			if len(edgeLabels) > 0 {
 				query = append(query, bson.M{"$match": bson.M{fieldLabel: bson.M{"$in": edgeLabels}}})
 			}
-			eCol := mg.ar.getEdgeCollection(mg.graph)
+			eCol := mg.ar.getEdgeReadCollection(mg.graph)
+			start := time.Now()
 			iter := eCol.Pipe(query).Iter()
 			defer iter.Close()
 			result := map[string]interface{}{}
@@ -626,6 +1333,9 @@ func (mg *Graph) GetInEdgeChannel(reqChan chan gdbi.ElementLookup, load bool, ed
 					o <- ri
 				}
 			}
+			err := iter.Err()
+			mg.ar.observe("GetInEdgeChannel", start, &err)
+			mg.ar.checkReadError(err)
 		}
 	}()
 	return o
@@ -639,7 +1349,7 @@ func (mg *Graph) GetOutList(ctx context.Context, key string, load bool, edgeLabe
 	vertexChan := make(chan string, 100)
 	go func() {
 		defer close(vertexChan)
-		eCol := mg.ar.getEdgeCollection(mg.graph)
+		eCol := mg.ar.getEdgeReadCollection(mg.graph)
 
 		selection := map[string]interface{}{
 			fieldSrc: key,
@@ -657,10 +1367,18 @@ func (mg *Graph) GetOutList(ctx context.Context, key string, load bool, edgeLabe
 			default:
 			}
 			if _, ok := result[fieldDst]; ok {
-				vertexChan <- result[fieldDst].(string)
+				select {
+				case <-ctx.Done():
+					return
+				case vertexChan <- result[fieldDst].(string):
+				}
 			} else if val, ok := result[fieldBundle]; ok {
 				for k := range val.(map[string]interface{}) {
-					vertexChan <- k
+					select {
+					case <-ctx.Done():
+						return
+					case vertexChan <- k:
+					}
 				}
 			}
 		}
@@ -668,7 +1386,7 @@ func (mg *Graph) GetOutList(ctx context.Context, key string, load bool, edgeLabe
 
 	go func() {
 		defer close(o)
-		vCol := mg.ar.getVertexCollection(mg.graph)
+		vCol := mg.ar.getVertexReadCollection(mg.graph)
 		for dst := range vertexChan {
 			q := vCol.FindId(dst)
 			if !load {
@@ -678,7 +1396,11 @@ func (mg *Graph) GetOutList(ctx context.Context, key string, load bool, edgeLabe
 			err := q.One(d)
 			if err == nil {
 				v := UnpackVertex(d)
-				o <- v
+				select {
+				case <-ctx.Done():
+					return
+				case o <- v:
+				}
 			}
 		}
 	}()
@@ -692,8 +1414,8 @@ func (mg *Graph) GetInList(ctx context.Context, key string, load bool, edgeLabel
 	o := make(chan aql.Vertex, 100)
 	go func() {
 		defer close(o)
-		eCol := mg.ar.getEdgeCollection(mg.graph)
-		vCol := mg.ar.getVertexCollection(mg.graph)
+		eCol := mg.ar.getEdgeReadCollection(mg.graph)
+		vCol := mg.ar.getVertexReadCollection(mg.graph)
 		selection := map[string]interface{}{
 			fieldDst: key,
 		}
@@ -716,7 +1438,11 @@ func (mg *Graph) GetInList(ctx context.Context, key string, load bool, edgeLabel
 			d := map[string]interface{}{}
 			if err := q.One(d); err == nil {
 				v := UnpackVertex(d)
-				o <- v
+				select {
+				case <-ctx.Done():
+					return
+				case o <- v:
+				}
 			}
 		}
 	}()
@@ -729,7 +1455,7 @@ func (mg *Graph) GetOutEdgeList(ctx context.Context, key string, load bool, edge
 	o := make(chan aql.Edge, 1000)
 	go func() {
 		defer close(o)
-		eCol := mg.ar.getEdgeCollection(mg.graph)
+		eCol := mg.ar.getEdgeReadCollection(mg.graph)
 		selection := map[string]interface{}{
 			fieldSrc: key,
 		}
@@ -737,16 +1463,25 @@ func (mg *Graph) GetOutEdgeList(ctx context.Context, key string, load bool, edge
 			selection[fieldLabel] = bson.M{"$in": edgeLabels}
 		}
 		iter := eCol.Find(selection).Iter()
+		defer iter.Close()
 		result := map[string]interface{}{}
 		for iter.Next(&result) {
 			if _, ok := result[fieldDst]; ok {
 				e := UnpackEdge(result)
-				o <- e
+				select {
+				case <-ctx.Done():
+					return
+				case o <- e:
+				}
 			} else if _, ok := result[fieldBundle]; ok {
 				bundle := UnpackBundle(result)
 				for k, v := range bundle.Bundle {
 					e := aql.Edge{Gid: bundle.Gid, Label: bundle.Label, From: bundle.From, To: k, Data: v}
-					o <- e
+					select {
+					case <-ctx.Done():
+						return
+					case o <- e:
+					}
 				}
 			}
 		}
@@ -761,7 +1496,7 @@ func (mg *Graph) GetOutBundleList(ctx context.Context, key string, load bool, ed
 	o := make(chan aql.Bundle, 1000)
 	go func() {
 		defer close(o)
-		eCol := mg.ar.getEdgeCollection(mg.graph)
+		eCol := mg.ar.getEdgeReadCollection(mg.graph)
 		selection := map[string]interface{}{
 			fieldSrc: key,
 		}
@@ -769,11 +1504,16 @@ func (mg *Graph) GetOutBundleList(ctx context.Context, key string, load bool, ed
 			selection[fieldLabel] = bson.M{"$in": edgeLabels}
 		}
 		iter := eCol.Find(selection).Iter()
+		defer iter.Close()
 		result := map[string]interface{}{}
 		for iter.Next(&result) {
 			if _, ok := result[fieldBundle]; ok {
 				bundle := UnpackBundle(result)
-				o <- bundle
+				select {
+				case <-ctx.Done():
+					return
+				case o <- bundle:
+				}
 			}
 		}
 	}()
@@ -787,7 +1527,7 @@ func (mg *Graph) GetInEdgeList(ctx context.Context, key string, load bool, edgeL
 	o := make(chan aql.Edge, 100)
 	go func() {
 		defer close(o)
-		eCol := mg.ar.getEdgeCollection(mg.graph)
+		eCol := mg.ar.getEdgeReadCollection(mg.graph)
 
 		selection := map[string]interface{}{
 			fieldDst: key,
@@ -796,10 +1536,15 @@ func (mg *Graph) GetInEdgeList(ctx context.Context, key string, load bool, edgeL
 			selection[fieldLabel] = bson.M{"$in": edgeLabels}
 		}
 		iter := eCol.Find(selection).Iter()
+		defer iter.Close()
 		result := map[string]interface{}{}
 		for iter.Next(&result) {
 			e := UnpackEdge(result)
-			o <- e
+			select {
+			case <-ctx.Done():
+				return
+			case o <- e:
+			}
 		}
 	}()
 	return o
@@ -807,13 +1552,21 @@ func (mg *Graph) GetInEdgeList(ctx context.Context, key string, load bool, edgeL
 
 // SetBundle adds a bundle to the graph
 func (mg *Graph) SetBundle(bundle aql.Bundle) error {
+	if err := aql.ValidateLabel(bundle.Label); err != nil {
+		return err
+	}
+	for _, d := range bundle.Bundle {
+		if err := aql.ValidateData(d); err != nil {
+			return err
+		}
+	}
 	eCol := mg.ar.getEdgeCollection(mg.graph)
 	if bundle.Gid != "" {
 		_, err := eCol.UpsertId(bundle.Gid, PackBundle(bundle))
 		return err
 	}
 	err := eCol.Insert(PackBundle(bundle))
-	mg.ts.Touch(mg.graph)
+	mg.ts.Touch(mg.graph, timestamp.ElementEdge)
 	return err
 }
 
@@ -821,9 +1574,9 @@ func (mg *Graph) SetBundle(bundle aql.Bundle) error {
 // loadProp is ignored
 func (mg *Graph) GetBundle(id string, loadProp bool) *aql.Bundle {
 	d := map[string]interface{}{}
-	eCol := mg.ar.getEdgeCollection(mg.graph)
+	eCol := mg.ar.getEdgeReadCollection(mg.graph)
 	q := eCol.FindId(id)
-	q.One(d)
+	mg.ar.checkReadError(q.One(d))
 	v := UnpackBundle(d)
 	return &v
 }
@@ -832,7 +1585,7 @@ func (mg *Graph) GetBundle(id string, loadProp bool) *aql.Bundle {
 func (mg *Graph) DelBundle(id string) error {
 	eCol := mg.ar.getEdgeCollection(mg.graph)
 	err := eCol.RemoveId(id)
-	mg.ts.Touch(mg.graph)
+	mg.ts.Touch(mg.graph, timestamp.ElementEdge)
 	return err
 }
 
@@ -841,7 +1594,7 @@ func (mg *Graph) VertexLabelScan(ctx context.Context, label string) chan string
 	out := make(chan string, 100)
 	go func() {
 		defer close(out)
-		vCol := mg.ar.getVertexCollection(mg.graph)
+		vCol := mg.ar.getVertexReadCollection(mg.graph)
 		selection := map[string]interface{}{
 			"label": label,
 		}
@@ -856,9 +1609,17 @@ func (mg *Graph) VertexLabelScan(ctx context.Context, label string) chan string
 			}
 			id := result["_id"]
 			if idb, ok := id.(bson.ObjectId); ok {
-				out <- idb.String()
+				select {
+				case <-ctx.Done():
+					return
+				case out <- idb.String():
+				}
 			} else {
-				out <- id.(string)
+				select {
+				case <-ctx.Done():
+					return
+				case out <- id.(string):
+				}
 			}
 		}
 	}()
@@ -870,7 +1631,7 @@ func (mg *Graph) EdgeLabelScan(ctx context.Context, label string) chan string {
 	out := make(chan string, 100)
 	go func() {
 		defer close(out)
-		eCol := mg.ar.getEdgeCollection(mg.graph)
+		eCol := mg.ar.getEdgeReadCollection(mg.graph)
 		selection := map[string]interface{}{
 			"label": label,
 		}
@@ -885,9 +1646,17 @@ func (mg *Graph) EdgeLabelScan(ctx context.Context, label string) chan string {
 			}
 			id := result["_id"]
 			if idb, ok := id.(bson.ObjectId); ok {
-				out <- idb.String()
+				select {
+				case <-ctx.Done():
+					return
+				case out <- idb.String():
+				}
 			} else {
-				out <- id.(string)
+				select {
+				case <-ctx.Done():
+					return
+				case out <- id.(string):
+				}
 			}
 		}
 	}()
@@ -0,0 +1,42 @@
+package mongo
+
+import (
+	"testing"
+	"time"
+)
+
+// cachedArachne returns a driver whose graph list is served from the graph
+// cache, so existence checks don't need a server
+func cachedArachne(graphs ...string) *Arachne {
+	ma := &Arachne{conf: Config{GraphCacheTTL: time.Minute}}
+	ma.graphCache.set(graphs, time.Minute, ma.graphCache.generation())
+	return ma
+}
+
+func isGraphNotFound(err error) bool {
+	e, ok := err.(*Error)
+	return ok && e.Err == ErrGraphNotFound
+}
+
+func TestEnforceEdgeUniquenessMissingGraph(t *testing.T) {
+	ma := cachedArachne("test")
+	if err := ma.EnforceEdgeUniqueness("missing"); !isGraphNotFound(err) {
+		t.Errorf("expected ErrGraphNotFound, got %v", err)
+	}
+}
+
+func TestIndexesMissingGraph(t *testing.T) {
+	ma := cachedArachne("test")
+	if err := ma.AddVertexIndex("missing", Index{Key: []string{"data.name"}}); !isGraphNotFound(err) {
+		t.Errorf("AddVertexIndex: expected ErrGraphNotFound, got %v", err)
+	}
+	if err := ma.EnsureDataIndex("missing", "name"); !isGraphNotFound(err) {
+		t.Errorf("EnsureDataIndex: expected ErrGraphNotFound, got %v", err)
+	}
+	if _, err := ma.ListIndexes("missing"); !isGraphNotFound(err) {
+		t.Errorf("ListIndexes: expected ErrGraphNotFound, got %v", err)
+	}
+	if _, err := ma.ListDataIndexes("missing"); !isGraphNotFound(err) {
+		t.Errorf("ListDataIndexes: expected ErrGraphNotFound, got %v", err)
+	}
+}
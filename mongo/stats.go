@@ -0,0 +1,101 @@
+package mongo
+
+import (
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"time"
+)
+
+// GraphStats describes the number of elements in a graph and the space used
+// by its vertex and edge collections
+type GraphStats struct {
+	VertexCount int
+	EdgeCount   int
+	// StorageSize and IndexSize are in bytes, summed over the vertex and
+	// edge collections
+	StorageSize int64
+	IndexSize   int64
+}
+
+// asInt64 converts the numeric types returned by server commands
+func asInt64(v interface{}) int64 {
+	switch n := v.(type) {
+	case int:
+		return int64(n)
+	case int32:
+		return int64(n)
+	case int64:
+		return n
+	case float64:
+		return int64(n)
+	}
+	return 0
+}
+
+// GraphStats returns element counts and storage sizes for `graph`, without
+// iterating over its elements. Collections that don't exist yet count as
+// empty
+func (ma *Arachne) GraphStats(graph string) (out *GraphStats, err error) {
+	defer ma.observe("GraphStats", time.Now(), &err)
+	if err := ma.requireGraph("GraphStats", graph); err != nil {
+		return nil, err
+	}
+
+	out = &GraphStats{}
+	db := ma.getReadSession().DB(ma.database)
+	for _, name := range []string{ma.vertexCollectionName(graph), ma.edgeCollectionName(graph)} {
+		stats := bson.M{}
+		if err := db.Run(bson.D{{Name: "collStats", Value: name}}, &stats); err != nil {
+			// the collections of a graph added with DeferIndexes don't exist
+			// until something is written to them
+			if isNamespaceNotFound(err) {
+				continue
+			}
+			return nil, &Error{Op: "GraphStats", Graph: graph, Err: err, Detail: "Failed to get stats for " + name}
+		}
+		count := int(asInt64(stats["count"]))
+		if name == ma.vertexCollectionName(graph) {
+			out.VertexCount = count
+		} else {
+			out.EdgeCount = count
+		}
+		out.StorageSize += asInt64(stats["storageSize"])
+		out.IndexSize += asInt64(stats["totalIndexSize"])
+	}
+	return out, nil
+}
+
+// labelCounts counts the documents of collection `c` grouped by label
+func labelCounts(c *mgo.Collection) (map[string]int64, error) {
+	pipe := []bson.M{{"$group": bson.M{"_id": "$" + fieldLabel, "count": bson.M{"$sum": 1}}}}
+	iter := c.Pipe(pipe).Iter()
+	out := map[string]int64{}
+	result := bson.M{}
+	for iter.Next(&result) {
+		label, _ := result["_id"].(string)
+		out[label] = asInt64(result["count"])
+	}
+	if err := iter.Close(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// VertexLabelCounts returns the number of vertices of each label in `graph`
+func (ma *Arachne) VertexLabelCounts(graph string) (map[string]int64, error) {
+	if err := ma.requireGraph("VertexLabelCounts", graph); err != nil {
+		return nil, err
+	}
+	out, err := labelCounts(ma.getVertexReadCollection(graph))
+	return out, wrapError("VertexLabelCounts", graph, err)
+}
+
+// EdgeLabelCounts returns the number of edges of each label in `graph`. A
+// bundle is counted once, regardless of the number of edges it holds
+func (ma *Arachne) EdgeLabelCounts(graph string) (map[string]int64, error) {
+	if err := ma.requireGraph("EdgeLabelCounts", graph); err != nil {
+		return nil, err
+	}
+	out, err := labelCounts(ma.getEdgeReadCollection(graph))
+	return out, wrapError("EdgeLabelCounts", graph, err)
+}
@@ -0,0 +1,80 @@
+package mongo
+
+import (
+	"context"
+	"github.com/bmeg/arachne/aql"
+)
+
+// VertexStream produces a channel of every vertex in the graph, reading them
+// from a cursor `batchSize` documents at a time. If `batchSize` is not
+// positive, BatchSize is used. The channel is closed once all vertices have
+// been sent, when `ctx` is cancelled or if the cursor fails
+func (mg *Graph) VertexStream(ctx context.Context, batchSize int) <-chan *aql.Vertex {
+	if batchSize <= 0 {
+		batchSize = BatchSize
+	}
+	vCol := mg.ar.getVertexReadCollection(mg.graph)
+	o := make(chan *aql.Vertex, 100)
+	go func() {
+		defer close(o)
+		iter := vCol.Find(nil).Batch(batchSize).Iter()
+		defer iter.Close()
+		result := map[string]interface{}{}
+		for iter.Next(&result) {
+			v := UnpackVertex(result)
+			result = map[string]interface{}{}
+			select {
+			case <-ctx.Done():
+				return
+			case o <- &v:
+			}
+		}
+		if err := iter.Err(); err != nil {
+			mg.ar.logger().Error("VertexStream error: %s", err)
+		}
+	}()
+	return o
+}
+
+// EdgeStream produces a channel of every edge in the graph, reading them from
+// a cursor `batchSize` documents at a time. Bundles are expanded into their
+// individual edges. If `batchSize` is not positive, BatchSize is used. The
+// channel is closed once all edges have been sent, when `ctx` is cancelled or
+// if the cursor fails
+func (mg *Graph) EdgeStream(ctx context.Context, batchSize int) <-chan *aql.Edge {
+	if batchSize <= 0 {
+		batchSize = BatchSize
+	}
+	eCol := mg.ar.getEdgeReadCollection(mg.graph)
+	o := make(chan *aql.Edge, 100)
+	go func() {
+		defer close(o)
+		iter := eCol.Find(nil).Batch(batchSize).Iter()
+		defer iter.Close()
+		result := map[string]interface{}{}
+		for iter.Next(&result) {
+			edges := []*aql.Edge{}
+			if _, ok := result[fieldDst]; ok {
+				e := UnpackEdge(result)
+				edges = append(edges, &e)
+			} else if _, ok := result[fieldBundle]; ok {
+				bundle := UnpackBundle(result)
+				for k, v := range bundle.Bundle {
+					edges = append(edges, &aql.Edge{Gid: bundle.Gid, Label: bundle.Label, From: bundle.From, To: k, Data: v})
+				}
+			}
+			result = map[string]interface{}{}
+			for _, e := range edges {
+				select {
+				case <-ctx.Done():
+					return
+				case o <- e:
+				}
+			}
+		}
+		if err := iter.Err(); err != nil {
+			mg.ar.logger().Error("EdgeStream error: %s", err)
+		}
+	}()
+	return o
+}
@@ -1,31 +1,76 @@
 package protoutil
 
 import (
+	"encoding/base64"
 	structpb "github.com/golang/protobuf/ptypes/struct"
 	"log"
+	"math"
+	"strconv"
 )
 
+// maxSafeInt is the largest integer a float64 NumberValue holds exactly
+const maxSafeInt = 1 << 53
+
+// wrapInt converts an integer to a NumberValue, or to a decimal StringValue
+// if it is too large to be stored in a float64 without losing precision
+func wrapInt(v int64) *structpb.Value {
+	if v > maxSafeInt || v < -maxSafeInt {
+		return &structpb.Value{Kind: &structpb.Value_StringValue{StringValue: strconv.FormatInt(v, 10)}}
+	}
+	return &structpb.Value{Kind: &structpb.Value_NumberValue{NumberValue: float64(v)}}
+}
+
+// wrapFloat converts a float to a NumberValue. NaN and Inf have no JSON
+// representation, so they become a NullValue
+func wrapFloat(v float64) *structpb.Value {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return &structpb.Value{Kind: &structpb.Value_NullValue{}}
+	}
+	return &structpb.Value{Kind: &structpb.Value_NumberValue{NumberValue: v}}
+}
+
+// wrapUint is the unsigned equivalent of wrapInt
+func wrapUint(v uint64) *structpb.Value {
+	if v > maxSafeInt {
+		return &structpb.Value{Kind: &structpb.Value_StringValue{StringValue: strconv.FormatUint(v, 10)}}
+	}
+	return &structpb.Value{Kind: &structpb.Value_NumberValue{NumberValue: float64(v)}}
+}
+
 //StructSet take value and add it to Struct s using key
 func StructSet(s *structpb.Struct, key string, value interface{}) {
 	vw := WrapValue(value)
 	s.Fields[key] = vw
 }
 
-// WrapValue takes a value and turns it into a protobuf structpb Value
+// WrapValue takes a value and turns it into a protobuf structpb Value.
+// Integers beyond +/-2^53 can't be represented exactly by a NumberValue, so
+// they are stored as decimal strings. NaN and Inf are stored as null and
+// binary data is stored as a base64 string
 func WrapValue(value interface{}) *structpb.Value {
 	switch v := value.(type) {
 	case string:
 		return &structpb.Value{Kind: &structpb.Value_StringValue{StringValue: v}}
 	case int:
-		return &structpb.Value{Kind: &structpb.Value_NumberValue{NumberValue: float64(v)}}
+		return wrapInt(int64(v))
 	case int64:
+		return wrapInt(v)
+	case uint:
+		return wrapUint(uint64(v))
+	case uint64:
+		return wrapUint(v)
+	case uint32:
 		return &structpb.Value{Kind: &structpb.Value_NumberValue{NumberValue: float64(v)}}
 	case int32:
 		return &structpb.Value{Kind: &structpb.Value_NumberValue{NumberValue: float64(v)}}
 	case float64:
-		return &structpb.Value{Kind: &structpb.Value_NumberValue{NumberValue: float64(v)}}
+		return wrapFloat(v)
+	case float32:
+		return wrapFloat(float64(v))
 	case bool:
 		return &structpb.Value{Kind: &structpb.Value_BoolValue{BoolValue: v}}
+	case []byte:
+		return &structpb.Value{Kind: &structpb.Value_StringValue{StringValue: base64.StdEncoding.EncodeToString(v)}}
 	case *structpb.Value:
 		return v
 	case []interface{}:
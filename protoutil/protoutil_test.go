@@ -0,0 +1,70 @@
+package protoutil
+
+import (
+	"bytes"
+	"encoding/base64"
+	"github.com/golang/protobuf/jsonpb"
+	structpb "github.com/golang/protobuf/ptypes/struct"
+	"math"
+	"strconv"
+	"testing"
+)
+
+func TestLargeIntRoundTrip(t *testing.T) {
+	var id int64 = 1<<62 + 1
+	var uid uint64 = 1<<63 + 1
+	s := AsStruct(map[string]interface{}{"id": id, "uid": uid, "small": int64(42)})
+	m := AsMap(s)
+
+	v, err := strconv.ParseInt(m["id"].(string), 10, 64)
+	if err != nil || v != id {
+		t.Errorf("int64 id changed: %v != %d", m["id"], id)
+	}
+	u, err := strconv.ParseUint(m["uid"].(string), 10, 64)
+	if err != nil || u != uid {
+		t.Errorf("uint64 id changed: %v != %d", m["uid"], uid)
+	}
+	if m["small"].(float64) != 42 {
+		t.Errorf("small int changed: %v", m["small"])
+	}
+}
+
+func TestIntBoundary(t *testing.T) {
+	if _, ok := WrapValue(int64(maxSafeInt)).Kind.(*structpb.Value_NumberValue); !ok {
+		t.Error("2^53 should be stored as a number")
+	}
+	if _, ok := WrapValue(int64(maxSafeInt + 1)).Kind.(*structpb.Value_StringValue); !ok {
+		t.Error("2^53+1 should be stored as a string")
+	}
+	if _, ok := WrapValue(int64(-maxSafeInt - 1)).Kind.(*structpb.Value_StringValue); !ok {
+		t.Error("-2^53-1 should be stored as a string")
+	}
+}
+
+func TestNaNInf(t *testing.T) {
+	for _, f := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+		s := AsStruct(map[string]interface{}{"value": f})
+		if _, ok := s.Fields["value"].Kind.(*structpb.Value_NullValue); !ok {
+			t.Errorf("%v should be stored as null", f)
+		}
+		if _, err := (&jsonpb.Marshaler{}).MarshalToString(s); err != nil {
+			t.Errorf("%v failed to marshal: %s", f, err)
+		}
+		if AsMap(s)["value"] != nil {
+			t.Errorf("%v should unwrap to nil", f)
+		}
+	}
+}
+
+func TestBytes(t *testing.T) {
+	data := []byte{0x00, 0xff, 0x10}
+	s := AsStruct(map[string]interface{}{"thumbnail": data})
+	v, ok := AsMap(s)["thumbnail"].(string)
+	if !ok {
+		t.Fatal("binary value should be stored as a string")
+	}
+	out, err := base64.StdEncoding.DecodeString(v)
+	if err != nil || !bytes.Equal(out, data) {
+		t.Errorf("binary value changed: %v", v)
+	}
+}
@@ -1,14 +1,40 @@
 package timestamp
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"sync"
 	"time"
 )
 
 //Timestamp records timestamps
 type Timestamp struct {
-	stamps sync.Map
+	stamps   sync.Map
+	lock     sync.Mutex
+	watchers map[string][]chan struct{}
+}
+
+//Element selects the vertex or edge timestamp of an entry
+type Element int
+
+const (
+	//ElementVertex is the timestamp updated when vertices change
+	ElementVertex Element = iota
+	//ElementEdge is the timestamp updated when edges change
+	ElementEdge
+)
+
+func (e Element) String() string {
+	if e == ElementEdge {
+		return "edge"
+	}
+	return "vertex"
+}
+
+//elementKey is the name an element timestamp is stored under
+func elementKey(name string, e Element) string {
+	return name + "\x00" + e.String()
 }
 
 //NewTimestamp creates a new Timestamp recorder
@@ -16,9 +42,61 @@ func NewTimestamp() Timestamp {
 	return Timestamp{stamps: sync.Map{}}
 }
 
-//Touch updates an entry in the timestamp
-func (ts *Timestamp) Touch(name string) {
-	ts.stamps.Store(name, fmt.Sprintf("%d", time.Now().UnixNano()))
+//Touch updates an entry in the timestamp, along with the timestamps of the
+//given elements. If no elements are given both the vertex and edge
+//timestamps are updated
+func (ts *Timestamp) Touch(name string, elements ...Element) {
+	if len(elements) == 0 {
+		elements = []Element{ElementVertex, ElementEdge}
+	}
+	t := fmt.Sprintf("%d", time.Now().UnixNano())
+	for _, e := range elements {
+		ts.stamps.Store(elementKey(name, e), t)
+	}
+	ts.stamps.Store(name, t)
+	ts.notify(name)
+}
+
+//notify wakes up the watchers of an entry without blocking. Notifications
+//are coalesced, a watcher that hasn't read its last event won't get another
+func (ts *Timestamp) notify(name string) {
+	ts.lock.Lock()
+	defer ts.lock.Unlock()
+	for _, c := range ts.watchers[name] {
+		select {
+		case c <- struct{}{}:
+		default:
+		}
+	}
+}
+
+//Watch returns a channel that receives an event each time the entry `name`
+//is touched. Call Unwatch with the same channel to stop receiving events
+func (ts *Timestamp) Watch(name string) <-chan struct{} {
+	ts.lock.Lock()
+	defer ts.lock.Unlock()
+	if ts.watchers == nil {
+		ts.watchers = map[string][]chan struct{}{}
+	}
+	c := make(chan struct{}, 1)
+	ts.watchers[name] = append(ts.watchers[name], c)
+	return c
+}
+
+//Unwatch stops sending events for entry `name` to a channel returned by Watch
+func (ts *Timestamp) Unwatch(name string, c <-chan struct{}) {
+	ts.lock.Lock()
+	defer ts.lock.Unlock()
+	w := ts.watchers[name]
+	for i := range w {
+		if w[i] == c {
+			ts.watchers[name] = append(w[:i], w[i+1:]...)
+			break
+		}
+	}
+	if len(ts.watchers[name]) == 0 {
+		delete(ts.watchers, name)
+	}
 }
 
 //Get gets the current timestamp
@@ -29,3 +107,31 @@ func (ts *Timestamp) Get(name string) string {
 	}
 	return o.(string)
 }
+
+//GetElement gets the current vertex or edge timestamp of an entry
+func (ts *Timestamp) GetElement(name string, e Element) string {
+	return ts.Get(elementKey(name, e))
+}
+
+//Save writes all of the recorded timestamps to `w` as JSON
+func (ts *Timestamp) Save(w io.Writer) error {
+	out := map[string]string{}
+	ts.stamps.Range(func(k, v interface{}) bool {
+		out[k.(string)] = v.(string)
+		return true
+	})
+	return json.NewEncoder(w).Encode(out)
+}
+
+//Load reads timestamps written by Save, replacing any existing entries with
+//the same name
+func (ts *Timestamp) Load(r io.Reader) error {
+	in := map[string]string{}
+	if err := json.NewDecoder(r).Decode(&in); err != nil {
+		return err
+	}
+	for k, v := range in {
+		ts.stamps.Store(k, v)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package timestamp
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestNotifyCoalesce(t *testing.T) {
+	ts := NewTimestamp()
+	c := ts.Watch("graph")
+	ts.Touch("graph")
+	ts.Touch("graph")
+	ts.Touch("other")
+	select {
+	case <-c:
+	default:
+		t.Fatal("no event after touch")
+	}
+	select {
+	case <-c:
+		t.Error("events were not coalesced")
+	default:
+	}
+	ts.Unwatch("graph", c)
+}
+
+func TestUnwatch(t *testing.T) {
+	ts := NewTimestamp()
+	c := ts.Watch("graph")
+	ts.Unwatch("graph", c)
+	done := make(chan bool)
+	go func() {
+		ts.Touch("graph")
+		ts.Touch("graph")
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("touch blocked after unwatch")
+	}
+	select {
+	case <-c:
+		t.Error("event delivered after unwatch")
+	default:
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	ts := NewTimestamp()
+	ts.Touch("a")
+	ts.Touch("b", ElementEdge)
+	buf := &bytes.Buffer{}
+	if err := ts.Save(buf); err != nil {
+		t.Fatal(err)
+	}
+	loaded := NewTimestamp()
+	if err := loaded.Load(buf); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"a", "b"} {
+		if loaded.Get(name) != ts.Get(name) {
+			t.Errorf("wrong timestamp for %s: %s != %s", name, loaded.Get(name), ts.Get(name))
+		}
+		for _, e := range []Element{ElementVertex, ElementEdge} {
+			if loaded.GetElement(name, e) != ts.GetElement(name, e) {
+				t.Errorf("wrong %s timestamp for %s", e, name)
+			}
+		}
+	}
+}
+
+func TestTouchElement(t *testing.T) {
+	ts := NewTimestamp()
+	ts.Touch("graph")
+	edge := ts.GetElement("graph", ElementEdge)
+	vertex := ts.GetElement("graph", ElementVertex)
+	time.Sleep(time.Millisecond)
+	ts.Touch("graph", ElementVertex)
+	if ts.GetElement("graph", ElementEdge) != edge {
+		t.Error("vertex touch changed the edge timestamp")
+	}
+	if ts.GetElement("graph", ElementVertex) == vertex {
+		t.Error("vertex timestamp not updated")
+	}
+	if ts.Get("graph") != ts.GetElement("graph", ElementVertex) {
+		t.Error("graph timestamp not updated")
+	}
+}